@@ -45,20 +45,10 @@ func bigIntToKey(num *big.Int) *string {
 	return &res
 }
 
+// keys are base-256 encoded integers, so compare them numerically,
+// leading zero bytes do not change key value
 func less(s1, s2 *string) bool {
-	if len(*s1) < len(*s2) {
-		return true
-	}
-	if len(*s1) > len(*s2) {
-		return false
-	}
-	for i := 0; i < len(*s1); i++ {
-		if (*s1)[i] == (*s2)[i] {
-			continue
-		}
-		return (*s1)[i] < (*s2)[i]
-	}
-	return false
+	return keyToBigInt(s1).Cmp(keyToBigInt(s2)) < 0
 }
 
 // можно придумать отображение получше, если знать максимальную длину ренджа, то есть если знать максимальный ключ.
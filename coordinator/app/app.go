@@ -6,8 +6,10 @@ import (
 	"sync"
 
 	"github.com/pg-sharding/spqr/coordinator"
+	"github.com/pg-sharding/spqr/coordinator/provider"
 	shhttp "github.com/pg-sharding/spqr/grpc"
 	"github.com/pg-sharding/spqr/pkg/config"
+	protos "github.com/pg-sharding/spqr/router/protos"
 	"github.com/wal-g/tracelog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -70,6 +72,8 @@ func (app *App) ServeGrpc(wg *sync.WaitGroup) error {
 	shhttp.Register(serv)
 	reflection.Register(serv)
 
+	protos.RegisterSequenceServiceServer(serv, provider.NewSequenceService(app.coordiantor))
+
 	//krserv := NewKeyRangeService(d)
 	//rrserv := NewRoutersService(d)
 
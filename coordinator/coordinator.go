@@ -5,6 +5,7 @@ import (
 
 	"github.com/pg-sharding/spqr/pkg/client"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/sequences"
 	"github.com/pg-sharding/spqr/pkg/models/shrule"
 	"github.com/pg-sharding/spqr/qdb"
 	"github.com/pg-sharding/spqr/world"
@@ -14,6 +15,7 @@ type Coordinator interface {
 	client.Interactor
 	kr.KeyRangeMgr
 	shrule.ShardingRulesMgr
+	sequences.SequenceMgr
 
 	RegisterRouter(ctx context.Context, r *qdb.Router) error
 	RegisterWorld(ctx context.Context, w world.World) error
@@ -8,6 +8,7 @@ import (
 	"github.com/pg-sharding/spqr/coordinator"
 	"github.com/pg-sharding/spqr/pkg/config"
 	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/hashfunction"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/pkg/models/shrule"
 	"github.com/pg-sharding/spqr/qdb"
@@ -48,10 +49,30 @@ type qdbCoordinator struct {
 }
 
 func (qc *qdbCoordinator) ListShardingRules(ctx context.Context) ([]*shrule.ShardingRule, error) {
-	return qc.db.ListShardingRules(ctx)
+	rules, err := qc.db.ListShardingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*shrule.ShardingRule
+	for _, rule := range rules {
+		ret = append(ret, shrule.ShardingRuleFromDB(rule))
+	}
+
+	return ret, nil
 }
 
 func (qc *qdbCoordinator) AddShardingRule(ctx context.Context, rule *shrule.ShardingRule) error {
+	if err := hashfunction.ValidateHashFunction(rule.HashFunction()); err != nil {
+		return err
+	}
+
+	// add sharding rule to metadb, so every router computes the same hash
+
+	if err := qc.db.AddShardingRule(ctx, rule.ToSQL()); err != nil {
+		return err
+	}
+
 	resp, err := qc.db.ListRouters(ctx)
 	if err != nil {
 		return err
@@ -69,7 +90,7 @@ func (qc *qdbCoordinator) AddShardingRule(ctx context.Context, rule *shrule.Shar
 
 		cl := routerproto.NewShardingRulesServiceClient(cc)
 		resp, err := cl.AddShardingRules(context.TODO(), &routerproto.AddShardingRuleRequest{
-			Rules: []*routerproto.ShardingRule{{Columns: rule.Columns()}},
+			Rules: []*routerproto.ShardingRule{rule.ToProto()},
 		})
 
 		if err != nil {
@@ -88,6 +109,10 @@ func (qc *qdbCoordinator) AddLocalTable(tname string) error {
 
 func (qc *qdbCoordinator) AddKeyRange(ctx context.Context, keyRange *kr.KeyRange) error {
 
+	if err := keyRange.Validate(); err != nil {
+		return err
+	}
+
 	// add key range to metadb
 
 	err := qc.db.AddKeyRange(ctx, keyRange.ToSQL())
@@ -195,11 +220,17 @@ func (qc *qdbCoordinator) ProcClient(ctx context.Context, nconn net.Conn) error
 			if err := func() error {
 				switch stmt := tstmt.(type) {
 				case *spqrparser.ShardingColumn:
-					err := qc.AddShardingRule(ctx, shrule.NewShardingRule([]string{stmt.ColName}))
+					err := qc.AddShardingRule(ctx, shrule.NewShardingRule(stmt.ColName, "", []string{stmt.ColName}, ""))
 					if err != nil {
 						return err
 					}
 
+					return nil
+				case *spqrparser.ShardingRule:
+					if err := qc.AddShardingRule(ctx, shrule.ShardingRuleFromSQL(stmt)); err != nil {
+						return err
+					}
+
 					return nil
 				case *spqrparser.RegisterRouter:
 					err := qc.RegisterRouter(ctx, qdb.NewRouter(stmt.Addr, stmt.ID))
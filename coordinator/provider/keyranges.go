@@ -19,7 +19,7 @@ type CoordinatorService struct {
 func (c CoordinatorService) AddShardingRules(ctx context.Context, request *protos.AddShardingRuleRequest) (*protos.AddShardingRuleReply, error) {
 
 	for _, rule := range request.Rules {
-		err := c.impl.AddShardingRule(ctx, shrule.NewShardingRule(rule.Columns))
+		err := c.impl.AddShardingRule(ctx, shrule.ShardingRuleFromProto(rule))
 
 		if err != nil {
 			return nil, err
@@ -38,9 +38,7 @@ func (c CoordinatorService) ListShardingRules(ctx context.Context, request *prot
 	var shardingRules []*protos.ShardingRule
 
 	for _, rule := range rules {
-		shardingRules = append(shardingRules, &protos.ShardingRule{
-			Columns: rule.Columns(),
-		})
+		shardingRules = append(shardingRules, rule.ToProto())
 	}
 
 	return &protos.ListShardingRuleReply{
@@ -49,12 +47,7 @@ func (c CoordinatorService) ListShardingRules(ctx context.Context, request *prot
 }
 
 func (c CoordinatorService) AddKeyRange(ctx context.Context, request *protos.AddKeyRangeRequest) (*protos.ModifyReply, error) {
-	err := c.impl.AddKeyRange(ctx, &kr.KeyRange{
-		LowerBound: []byte(request.KeyRangeInfo.KeyRange.LowerBound),
-		UpperBound: []byte(request.KeyRangeInfo.KeyRange.UpperBound),
-		ID:         request.KeyRangeInfo.Krid,
-		ShardID:    request.KeyRangeInfo.ShardId,
-	})
+	err := c.impl.AddKeyRange(ctx, kr.KeyRangeFromProto(request.KeyRangeInfo))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/pg-sharding/spqr/coordinator"
+	"github.com/pg-sharding/spqr/pkg/models/sequences"
+	protos "github.com/pg-sharding/spqr/router/protos"
+	"golang.org/x/xerrors"
+)
+
+func (qc *qdbCoordinator) NextRange(ctx context.Context, name string, count int64) (*sequences.Range, error) {
+	if count <= 0 {
+		return nil, xerrors.Errorf("invalid size %v of sequence %v range", count, name)
+	}
+
+	first, err := qc.db.AllocateSequenceRange(ctx, name, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return sequences.NewRange(name, first, count), nil
+}
+
+// SequenceService hands out blocks of sequence values to routers
+type SequenceService struct {
+	protos.UnimplementedSequenceServiceServer
+
+	impl coordinator.Coordinator
+}
+
+func (s *SequenceService) NextRange(ctx context.Context, request *protos.NextRangeRequest) (*protos.NextRangeReply, error) {
+	r, err := s.impl.NextRange(ctx, request.Name, request.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &protos.NextRangeReply{
+		Range: r.ToProto(),
+	}, nil
+}
+
+var _ protos.SequenceServiceServer = &SequenceService{}
+
+func NewSequenceService(impl coordinator.Coordinator) *SequenceService {
+	return &SequenceService{
+		impl: impl,
+	}
+}
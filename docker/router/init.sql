@@ -1,3 +1,3 @@
 --CREATE SHARDING COLUMN w_id;
---ADD KEY RANGE 1 10 sh1 krid1;
---ADD KEY RANGE 11 20 sh2 krid2;
+--ADD KEY RANGE 1 11 sh1 krid1;
+--ADD KEY RANGE 11 21 sh2 krid2;
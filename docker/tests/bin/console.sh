@@ -12,12 +12,12 @@ psql "host=spqr_router_1_1 sslmode=disable user=user1 dbname=db1 port=7432" -c '
     exit 1
 }
 
-psql "host=spqr_router_1_1 sslmode=disable user=user1 dbname=db1 port=7432" -c 'ADD KEY RANGE 1 10 sh1 krid1;' || {
+psql "host=spqr_router_1_1 sslmode=disable user=user1 dbname=db1 port=7432" -c 'ADD KEY RANGE 1 11 sh1 krid1;' || {
     echo "ERROR: tests failed"
     exit 1
 }
 
-psql "host=spqr_router_1_1 sslmode=disable user=user1 dbname=db1 port=7432" -c 'ADD KEY RANGE 11 20 sh2 krid2;' || {
+psql "host=spqr_router_1_1 sslmode=disable user=user1 dbname=db1 port=7432" -c 'ADD KEY RANGE 11 21 sh2 krid2;' || {
     echo "ERROR: tests failed"
     exit 1
 }
@@ -9,12 +9,12 @@ psql "host=spqr_coordinator sslmode=disable user=user1 dbname=db1 port=7002" -c
 	exit 1
 }
 
-psql "host=spqr_coordinator sslmode=disable user=user1 dbname=db1 port=7002" -c 'add key range 1 10 sh1 krid1' || {
+psql "host=spqr_coordinator sslmode=disable user=user1 dbname=db1 port=7002" -c 'add key range 1 11 sh1 krid1' || {
 	echo "ERROR: tests failed"
 	exit 1
 }
 
-psql "host=spqr_coordinator sslmode=disable user=user1 dbname=db1 port=7002" -c 'add key range 11 20 sh2 krid2' || {
+psql "host=spqr_coordinator sslmode=disable user=user1 dbname=db1 port=7002" -c 'add key range 11 21 sh2 krid2' || {
 	echo "ERROR: tests failed"
 	exit 1
 }
@@ -12,6 +12,7 @@ type Client interface {
 	ID() string
 
 	ReplyErr(errmsg string) error
+	ReplyErrWithCode(code string, errmsg string) error
 	ReplyNotice(msg string) error
 	DefaultReply() error
 
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/hashfunction"
 	"github.com/pg-sharding/spqr/pkg/models/datashards"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/pkg/models/shrule"
@@ -146,7 +149,7 @@ func (pi *PSQLInteractor) KeyRanges(krs []*kr.KeyRange, cl Client) error {
 
 	for _, keyRange := range krs {
 		if err := cl.Send(&pgproto3.DataRow{
-			Values: [][]byte{[]byte(fmt.Sprintf("key range %v mapped to datashard %s", keyRange.ID, keyRange.ShardID))},
+			Values: [][]byte{[]byte(fmt.Sprintf("key range %v [%s, %s) of type %s mapped to datashard %s", keyRange.ID, keyRange.LowerBound, keyRange.UpperBound, keyRange.KeyType, keyRange.ShardID))},
 		}); err != nil {
 			tracelog.InfoLogger.Print(err)
 		}
@@ -293,30 +296,75 @@ func (pi *PSQLInteractor) Shards(ctx context.Context, shards []*datashards.DataS
 func (pi *PSQLInteractor) ShardingRules(ctx context.Context, rules []*shrule.ShardingRule, cl Client) error {
 	tracelog.InfoLogger.Printf("listing sharding rules")
 
-	for _, rule := range rules {
-		if err := cl.Send(&pgproto3.DataRow{
-			Values: [][]byte{[]byte(fmt.Sprintf("colmns-match sharding rule with colmn set: %+v", rule.Columns()))},
-		}); err != nil {
+	for _, msg := range []pgproto3.BackendMessage{
+		&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+			{
+				Name:                 []byte("sharding rule id"),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          25,
+				DataTypeSize:         -1,
+				TypeModifier:         -1,
+				Format:               0,
+			},
+			{
+				Name:                 []byte("table"),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          25,
+				DataTypeSize:         -1,
+				TypeModifier:         -1,
+				Format:               0,
+			},
+			{
+				Name:                 []byte("columns"),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          25,
+				DataTypeSize:         -1,
+				TypeModifier:         -1,
+				Format:               0,
+			},
+			{
+				Name:                 []byte("hash function"),
+				TableOID:             0,
+				TableAttributeNumber: 0,
+				DataTypeOID:          25,
+				DataTypeSize:         -1,
+				TypeModifier:         -1,
+				Format:               0,
+			},
+		},
+		},
+	} {
+		if err := cl.Send(msg); err != nil {
 			tracelog.InfoLogger.Print(err)
+			return err
 		}
 	}
 
-	if err := cl.Send(&pgproto3.DataRow{
-		Values: [][]byte{[]byte(fmt.Sprintf("local node"))},
-	}); err != nil {
-		tracelog.InfoLogger.Print(err)
-	}
-
-	for _, msg := range []pgproto3.BackendMessage{
-		&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")},
-		&pgproto3.ReadyForQuery{},
-	} {
-		if err := cl.Send(msg); err != nil {
+	for _, rule := range rules {
+		tableName := rule.TableName()
+		if tableName == "" {
+			tableName = "*"
+		}
+		hashFunction := rule.HashFunction()
+		if hashFunction == "" {
+			hashFunction = hashfunction.HashFunctionIdent
+		}
+		if err := cl.Send(&pgproto3.DataRow{
+			Values: [][]byte{
+				[]byte(rule.ID()),
+				[]byte(tableName),
+				[]byte(strings.Join(rule.Columns(), ", ")),
+				[]byte(hashFunction),
+			},
+		}); err != nil {
 			tracelog.InfoLogger.Print(err)
 		}
 	}
 
-	return nil
+	return pi.completeMsg(len(rules), cl)
 }
 
 func (pi *PSQLInteractor) AddShardingRule(ctx context.Context, rule *shrule.ShardingRule, cl Client) error {
@@ -334,7 +382,7 @@ func (pi *PSQLInteractor) AddShardingRule(ctx context.Context, rule *shrule.Shar
 			},
 		},
 		},
-		&pgproto3.DataRow{Values: [][]byte{[]byte(fmt.Sprintf("created sharding column %s, err %w", rule.Columns()))}},
+		&pgproto3.DataRow{Values: [][]byte{[]byte(fmt.Sprintf("created sharding rule %s for columns %s", rule.ID(), strings.Join(rule.Columns(), ", ")))}},
 		&pgproto3.CommandComplete{},
 		&pgproto3.ReadyForQuery{},
 	} {
@@ -345,3 +393,134 @@ func (pi *PSQLInteractor) AddShardingRule(ctx context.Context, rule *shrule.Shar
 
 	return nil
 }
+
+// ExplainedRoute is a datashard route of query, explained by EXPLAIN ROUTE.
+// Empty values are shown as nulls
+type ExplainedRoute struct {
+	StmtType     string
+	State        string
+	ShardingRule string
+	KeyRange     string
+	Shard        string
+	RW           bool
+}
+
+// nullable returns null for empty value
+func nullable(val string) []byte {
+	if val == "" {
+		return nil
+	}
+
+	return []byte(val)
+}
+
+func (pi *PSQLInteractor) ExplainRoute(ctx context.Context, routes []ExplainedRoute, cl Client) error {
+	var fields []pgproto3.FieldDescription
+	for _, name := range []string{"statement type", "routing state", "sharding rule", "key range", "shard"} {
+		fields = append(fields, pgproto3.FieldDescription{
+			Name:                 []byte(name),
+			TableOID:             0,
+			TableAttributeNumber: 0,
+			DataTypeOID:          25,
+			DataTypeSize:         -1,
+			TypeModifier:         -1,
+			Format:               0,
+		})
+	}
+	fields = append(fields, pgproto3.FieldDescription{
+		Name:                 []byte("rw"),
+		TableOID:             0,
+		TableAttributeNumber: 0,
+		DataTypeOID:          16,
+		DataTypeSize:         1,
+		TypeModifier:         -1,
+		Format:               0,
+	})
+
+	if err := cl.Send(&pgproto3.RowDescription{Fields: fields}); err != nil {
+		tracelog.InfoLogger.Print(err)
+		return err
+	}
+
+	for _, route := range routes {
+		var rw []byte
+		switch {
+		case route.Shard == "":
+		case route.RW:
+			rw = []byte("t")
+		default:
+			rw = []byte("f")
+		}
+
+		if err := cl.Send(&pgproto3.DataRow{
+			Values: [][]byte{
+				nullable(route.StmtType),
+				nullable(route.State),
+				nullable(route.ShardingRule),
+				nullable(route.KeyRange),
+				nullable(route.Shard),
+				rw,
+			},
+		}); err != nil {
+			tracelog.InfoLogger.Print(err)
+		}
+	}
+
+	return pi.completeMsg(len(routes), cl)
+}
+
+// host roles of SHOW hosts
+const (
+	hostPrimary = "primary"
+	hostReplica = "replica"
+	hostUnknown = "unknown"
+)
+
+func (pi *PSQLInteractor) Hosts(ctx context.Context, hosts []*conn.HostStatus, cl Client) error {
+	tracelog.InfoLogger.Printf("listing hosts")
+
+	var fields []pgproto3.FieldDescription
+	for _, name := range []string{"shard", "host", "role", "lsn", "replay lag", "error"} {
+		fields = append(fields, pgproto3.FieldDescription{
+			Name:                 []byte(name),
+			TableOID:             0,
+			TableAttributeNumber: 0,
+			DataTypeOID:          25,
+			DataTypeSize:         -1,
+			TypeModifier:         -1,
+			Format:               0,
+		})
+	}
+
+	if err := cl.Send(&pgproto3.RowDescription{Fields: fields}); err != nil {
+		tracelog.InfoLogger.Print(err)
+		return err
+	}
+
+	for _, host := range hosts {
+		role, lag, errmsg := hostReplica, host.Lag.String(), ""
+		switch {
+		case host.Err != nil:
+			role, lag, errmsg = hostUnknown, "", host.Err.Error()
+		case host.Primary:
+			role, lag = hostPrimary, ""
+		case host.Lag == conn.UnknownLag:
+			lag = "unknown"
+		}
+
+		if err := cl.Send(&pgproto3.DataRow{
+			Values: [][]byte{
+				[]byte(host.Shard),
+				[]byte(host.Host),
+				[]byte(role),
+				nullable(host.LSN),
+				nullable(lag),
+				nullable(errmsg),
+			},
+		}); err != nil {
+			tracelog.InfoLogger.Print(err)
+		}
+	}
+
+	return pi.completeMsg(len(hosts), cl)
+}
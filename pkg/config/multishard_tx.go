@@ -0,0 +1,15 @@
+package config
+
+// MultiShardTxPolicy defines how transactions, spanning
+// several datashards, are executed
+type MultiShardTxPolicy string
+
+const (
+	// COMMIT is sent to every datashard, so transaction may be committed partially. Default policy
+	MultiShardTxAllow = MultiShardTxPolicy("ALLOW")
+	// transaction is committed with two-phase commit. Requires SHARDING qrouter, which
+	// records commit decisions in shared qdb, and max_prepared_transactions on datashards
+	MultiShardTxTwoPhase = MultiShardTxPolicy("2PC")
+	// transaction is rejected, once it touches second datashard
+	MultiShardTxReject = MultiShardTxPolicy("REJECT")
+)
@@ -8,7 +8,29 @@ const (
 	ShardQrouter = QrouterType("SHARDING")
 )
 
+// NoShardingKeyPolicy defines how statements without
+// sharding key predicate are executed
+type NoShardingKeyPolicy string
+
+const (
+	NoShardingKeyReject    = NoShardingKeyPolicy("REJECT")
+	NoShardingKeyBroadcast = NoShardingKeyPolicy("BROADCAST")
+	NoShardingKeyWorld     = NoShardingKeyPolicy("WORLD")
+)
+
 type QrouterConfig struct {
 	Qtype      string `json:"qrouter_type" toml:"qrouter_type" yaml:"qrouter_type"`
 	LocalShard string `json:"local_shard" toml:"local_shard" yaml:"local_shard"`
+
+	// qdb, which keeps topology of sharding router
+	QdbAddr string `json:"qdb_addr" toml:"qdb_addr" yaml:"qdb_addr"`
+
+	// DELETE without sharding key is rejected by default
+	DeleteNoShardingKeyPolicy NoShardingKeyPolicy `json:"delete_no_sharding_key_policy" toml:"delete_no_sharding_key_policy" yaml:"delete_no_sharding_key_policy"`
+
+	// blocks of sequence values are requested from coordinator, if its address is set,
+	// and allocated in qdb of router otherwise. Only SHARDING router allocates blocks in its qdb
+	CoordinatorAddr   string         `json:"coordinator_addr" toml:"coordinator_addr" yaml:"coordinator_addr"`
+	SequenceBlockSize int64          `json:"sequence_block_size" toml:"sequence_block_size" yaml:"sequence_block_size"`
+	Sequences         []*SequenceCfg `json:"sequences" toml:"sequences" yaml:"sequences"`
 }
@@ -0,0 +1,15 @@
+package config
+
+// ReadPolicy defines hosts of datashard, where read-only queries are executed.
+// SELECT, calling user-defined function, which modifies data, is considered read-only,
+// so it is routed to primary by routing hint target_session_attrs=read-write
+type ReadPolicy string
+
+const (
+	// every query is executed on primary
+	ReadPolicyPrimaryOnly = ReadPolicy("PRIMARY_ONLY")
+	// read-only queries are executed on replicas, or on primary, if no replica is available
+	ReadPolicyPreferReplica = ReadPolicy("PREFER_REPLICA")
+	// read-only queries are executed on replicas only
+	ReadPolicyReplicaOnly = ReadPolicy("REPLICA_ONLY")
+)
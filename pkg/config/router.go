@@ -9,6 +9,9 @@ import (
 )
 
 type RouterCfg struct {
+	// identifies router among routers, sharing qdb. Transactions, prepared
+	// by router, are marked with it, so router recovers only its own transactions
+	RouterID      string `json:"router_id" toml:"router_id" yaml:"router_id"`
 	LogLevel      string `json:"log_level" toml:"log_level" yaml:"log_level"` // TODO usage
 	HttpAddr      string `json:"http_addr" toml:"http_addr" yaml:"http_addr"`
 	WorldHttpAddr string `json:"world_http_addr" toml:"world_http_addr" yaml:"world_http_addr"`
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type RouteKeyCfg struct {
 	Usr string `json:"usr" yaml:"usr" toml:"usr"`
 	DB  string `json:"db" yaml:"db" toml:"db"`
@@ -17,6 +19,12 @@ type RulesCfg struct {
 	PROTO              string `json:"proto" toml:"proto" yaml:"proto"`
 	WorldShardFallback bool   `json:"world_shard_fallback" toml:"world_shard_fallback" yaml:"world_shard_fallback"`
 
+	// replicas, lagging behind primary more, do not execute read-only queries. Not limited by default
+	MaxReplicaLag time.Duration `json:"max_replica_lag" toml:"max_replica_lag" yaml:"max_replica_lag"`
+	// time to wait for replica to replay last writes of client session, before read-only query
+	// is executed on primary. Query is executed on primary immediately by default
+	ReadYourWritesTimeout time.Duration `json:"read_your_writes_timeout" toml:"read_your_writes_timeout" yaml:"read_your_writes_timeout"`
+
 	// listen cfg
 	TLSCfg TLSConfig `json:"tls" yaml:"tls" toml:"tls"`
 
@@ -38,6 +46,12 @@ type FRRule struct {
 
 	PoolingMode PoolingMode `json:"pooling_mode" yaml:"pooling_mode" toml:"pooling_mode"`
 
+	// transactions, spanning several datashards, are committed on every datashard by default
+	MultiShardTxPolicy MultiShardTxPolicy `json:"multi_shard_tx_policy" yaml:"multi_shard_tx_policy" toml:"multi_shard_tx_policy"`
+
+	// read-only queries are executed on primary by default
+	ReadPolicy ReadPolicy `json:"read_policy" yaml:"read_policy" toml:"read_policy"`
+
 	// TODO: validate!
 	AuthRule AuthRule `json:"auth_rule" yaml:"auth_rule" toml:"auth_rule"`
 }
@@ -0,0 +1,9 @@
+package config
+
+// SequenceCfg declares column of sharded table,
+// filled by router with values of global sequence on INSERT
+type SequenceCfg struct {
+	Name   string `json:"name" toml:"name" yaml:"name"`
+	Table  string `json:"table" toml:"table" yaml:"table"`
+	Column string `json:"column" toml:"column" yaml:"column"`
+}
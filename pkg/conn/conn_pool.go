@@ -4,10 +4,12 @@ import (
 	"crypto/tls"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/pg-sharding/spqr/pkg/config"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/wal-g/tracelog"
+	"golang.org/x/xerrors"
 )
 
 type Pool interface {
@@ -112,6 +114,8 @@ type ConnPool interface {
 	Check(key kr.ShardKey) bool
 
 	UpdateHostStatus(shard, hostname string, rw bool) error
+	// UpdateHostLag updates replay delay of replica, reported by watchdog
+	UpdateHostLag(shard, hostname string, lag time.Duration) error
 
 	List() []DBInstance
 }
@@ -123,6 +127,11 @@ type InstancePoolImpl struct {
 	mu sync.Mutex
 
 	primaries map[string]string
+	// replay delays of replicas by host
+	lags map[string]time.Duration
+
+	// hosts, where read-only connections are established
+	readPolicy config.ReadPolicy
 
 	tlscfg *tls.Config
 }
@@ -149,6 +158,30 @@ func (s *InstancePoolImpl) UpdateHostStatus(shard, hostname string, rw bool) err
 	return nil
 }
 
+func (s *InstancePoolImpl) UpdateHostLag(_, hostname string, lag time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lags[hostname] = lag
+
+	return nil
+}
+
+// lagging checks if replica lags behind primary more than allowed.
+// Replica of unknown delay is lagging
+func (s *InstancePoolImpl) lagging(hostname string) bool {
+	maxLag := config.RouterConfig().RouterConfig.MaxReplicaLag
+	if maxLag <= 0 {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lag, ok := s.lags[hostname]
+	return ok && (lag == UnknownLag || lag > maxLag)
+}
+
 func (s *InstancePoolImpl) Check(key kr.ShardKey) bool {
 
 	return true
@@ -169,26 +202,65 @@ func (s *InstancePoolImpl) Connection(key kr.ShardKey) (DBInstance, error) {
 
 	switch key.RW {
 	case true:
-		var pr string
-		var ok bool
-		pr, ok = s.primaries[key.Name]
-		if !ok {
-			pr = config.RouterConfig().RouterConfig.ShardMapping[key.Name].Hosts[0].ConnAddr
-		}
-		return s.poolRW.Connection(key.Name, pr)
+		return s.poolRW.Connection(key.Name, s.primary(key.Name))
 	case false:
 		tracelog.InfoLogger.Printf("get conn to %s", key.Name)
-		hosts := config.RouterConfig().RouterConfig.ShardMapping[key.Name].Hosts
-		rand.Shuffle(len(hosts), func(i, j int) {
-			hosts[j], hosts[i] = hosts[i], hosts[j]
-		})
-		return s.poolRO.Connection(key.Name, hosts[0].ConnAddr)
+		return s.replicaConnection(key.Name)
 	default:
 		panic("never")
 	}
 
 }
 
+// primary returns host of datashard primary. First host
+// of datashard is primary, until watchdog finds another one
+func (s *InstancePoolImpl) primary(shard string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pr, ok := s.primaries[shard]; ok {
+		return pr
+	}
+
+	return config.RouterConfig().RouterConfig.ShardMapping[shard].Hosts[0].ConnAddr
+}
+
+// replicaConnection connects to random available replica of datashard, which does not lag
+// behind primary. Primary is used, if there is no such replica and read policy allows it
+func (s *InstancePoolImpl) replicaConnection(shard string) (DBInstance, error) {
+	pr := s.primary(shard)
+
+	var replicas []string
+	for _, host := range config.RouterConfig().RouterConfig.ShardMapping[shard].Hosts {
+		if host.ConnAddr == pr {
+			continue
+		}
+		if s.lagging(host.ConnAddr) {
+			tracelog.InfoLogger.Printf("skip replica %v of datashard %v, lagging behind primary", host.ConnAddr, shard)
+			continue
+		}
+		replicas = append(replicas, host.ConnAddr)
+	}
+
+	rand.Shuffle(len(replicas), func(i, j int) {
+		replicas[j], replicas[i] = replicas[i], replicas[j]
+	})
+
+	for _, host := range replicas {
+		pgi, err := s.poolRO.Connection(shard, host)
+		if err == nil {
+			return pgi, nil
+		}
+		tracelog.ErrorLogger.Printf("failed to connect to replica %v of datashard %v: %v", host, shard, err)
+	}
+
+	if s.readPolicy == config.ReadPolicyReplicaOnly {
+		return nil, xerrors.Errorf("no replica of datashard %v is available", shard)
+	}
+
+	return s.poolRO.Connection(shard, pr)
+}
+
 func (s *InstancePoolImpl) Put(shkey kr.ShardKey, sh DBInstance) error {
 
 	switch shkey.RW {
@@ -201,10 +273,12 @@ func (s *InstancePoolImpl) Put(shkey kr.ShardKey, sh DBInstance) error {
 	}
 }
 
-func NewConnPool(mapping map[string]*config.ShardCfg) ConnPool {
+func NewConnPool(mapping map[string]*config.ShardCfg, readPolicy config.ReadPolicy) ConnPool {
 	return &InstancePoolImpl{
-		poolRW:    NewPool(mapping),
-		poolRO:    NewPool(mapping),
-		primaries: map[string]string{},
+		poolRW:     NewPool(mapping),
+		poolRO:     NewPool(mapping),
+		primaries:  map[string]string{},
+		lags:       map[string]time.Duration{},
+		readPolicy: readPolicy,
 	}
 }
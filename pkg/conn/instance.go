@@ -14,7 +14,8 @@ import (
 const SSLREQ = 80877103
 const CANCELREQ = 80877102
 const TXREL = 73
-const NOTXREL = 86
+const NOTXREL = 84
+const TXERR = 69
 
 type InstanceStatus string
 
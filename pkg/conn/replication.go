@@ -0,0 +1,88 @@
+package conn
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgproto3/v2"
+	"golang.org/x/xerrors"
+)
+
+// HostStatus is replication status of datashard host, collected by watchdog
+type HostStatus struct {
+	Shard string
+	Host  string
+
+	Primary bool
+	// last WAL position: written on primary, replayed on replica
+	LSN string
+	// replay delay of replica, UnknownLag if it is not known
+	Lag time.Duration
+
+	// error of last check, host status is unknown
+	Err error
+}
+
+// UnknownLag is replay delay of replica, which is unreachable
+// or has never replayed any transaction
+const UnknownLag = time.Duration(-1)
+
+// replay delay is zero, if replica receives WAL and replayed all received WAL,
+// otherwise it is age of last replayed transaction. Replica, which lost its upstream,
+// has no WAL receiver process: pid of receiver is visible without pg_read_all_stats
+const replicationStatusQuery = `SELECT pg_is_in_recovery(),
+	CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END,
+	CASE WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn()
+			AND EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE pid IS NOT NULL) THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END`
+
+// ReplicationStatus queries replication status of datashard host
+func ReplicationStatus(shard string, pgi DBInstance) (*HostStatus, error) {
+	if err := pgi.Send(&pgproto3.Query{String: replicationStatusQuery}); err != nil {
+		return nil, err
+	}
+
+	var row [][]byte
+	var errmsg string
+
+	for {
+		msg, err := pgi.Receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.DataRow:
+			row = append([][]byte{}, v.Values...)
+		case *pgproto3.ErrorResponse:
+			errmsg = v.Message
+		case *pgproto3.ReadyForQuery:
+			if errmsg != "" {
+				return nil, xerrors.Errorf("failed to check replication status of %v: %s", pgi.Hostname(), errmsg)
+			}
+			if len(row) != 3 {
+				return nil, xerrors.Errorf("unexpected replication status of %v", pgi.Hostname())
+			}
+
+			status := &HostStatus{
+				Shard:   shard,
+				Host:    pgi.Hostname(),
+				Primary: string(row[0]) == "f",
+				LSN:     string(row[1]),
+				Lag:     UnknownLag,
+			}
+
+			// delay is NULL, if replica has not replayed any transaction
+			if row[2] != nil {
+				lag, err := strconv.ParseFloat(string(row[2]), 64)
+				if err != nil {
+					return nil, xerrors.Errorf("unexpected replay delay %s of %v", row[2], pgi.Hostname())
+				}
+				status.Lag = time.Duration(lag * float64(time.Second))
+			}
+
+			return status, nil
+		}
+	}
+}
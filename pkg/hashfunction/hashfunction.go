@@ -0,0 +1,219 @@
+package hashfunction
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// Hash functions, applicable to sharding key values before
+// key range matching. Hash value is represented as decimal
+// unsigned 32-bit integer.
+const (
+	HashFunctionIdent    = "ident"
+	HashFunctionMurmur3  = "murmur3"
+	HashFunctionHashInt8 = "hashint8"
+	HashFunctionHashText = "hashtext"
+)
+
+func ValidateHashFunction(name string) error {
+	switch name {
+	case "", HashFunctionIdent, HashFunctionMurmur3, HashFunctionHashInt8, HashFunctionHashText:
+		return nil
+	default:
+		return xerrors.Errorf("unknown hash function %v", name)
+	}
+}
+
+// ApplyHashFunction hashes text representation of sharding key value
+func ApplyHashFunction(name string, val []byte) ([]byte, error) {
+	var h uint32
+
+	switch name {
+	case "", HashFunctionIdent:
+		return val, nil
+	case HashFunctionMurmur3:
+		h = murmur3(val, 0)
+	case HashFunctionHashInt8:
+		i, err := strconv.ParseInt(string(val), 10, 64)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to apply %s to %s: %w", name, val, err)
+		}
+		h = hashInt8(i)
+	case HashFunctionHashText:
+		h = hashBytes(val)
+	default:
+		return nil, xerrors.Errorf("unknown hash function %v", name)
+	}
+
+	return []byte(strconv.FormatUint(uint64(h), 10)), nil
+}
+
+// murmur3 is MurmurHash3 x86 32-bit variant
+func murmur3(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	h := seed
+	nblocks := len(data) / 4
+
+	for i := 0; i < nblocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	tail := data[nblocks*4:]
+	var k uint32
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
+
+// PostgreSQL hash_bytes (Bob Jenkins' lookup3), as computed
+// on little-endian platforms
+
+func mix(a, b, c uint32) (uint32, uint32, uint32) {
+	a -= c
+	a ^= bits.RotateLeft32(c, 4)
+	c += b
+	b -= a
+	b ^= bits.RotateLeft32(a, 6)
+	a += c
+	c -= b
+	c ^= bits.RotateLeft32(b, 8)
+	b += a
+	a -= c
+	a ^= bits.RotateLeft32(c, 16)
+	c += b
+	b -= a
+	b ^= bits.RotateLeft32(a, 19)
+	a += c
+	c -= b
+	c ^= bits.RotateLeft32(b, 4)
+	b += a
+	return a, b, c
+}
+
+func final(a, b, c uint32) uint32 {
+	c ^= b
+	c -= bits.RotateLeft32(b, 14)
+	a ^= c
+	a -= bits.RotateLeft32(c, 11)
+	b ^= a
+	b -= bits.RotateLeft32(a, 25)
+	c ^= b
+	c -= bits.RotateLeft32(b, 16)
+	a ^= c
+	a -= bits.RotateLeft32(c, 4)
+	b ^= a
+	b -= bits.RotateLeft32(a, 14)
+	c ^= b
+	c -= bits.RotateLeft32(b, 24)
+	return c
+}
+
+func hashBytes(k []byte) uint32 {
+	var a, b, c uint32
+	a = 0x9e3779b9 + uint32(len(k)) + 3923095
+	b, c = a, a
+
+	for len(k) >= 12 {
+		a += binary.LittleEndian.Uint32(k)
+		b += binary.LittleEndian.Uint32(k[4:])
+		c += binary.LittleEndian.Uint32(k[8:])
+		a, b, c = mix(a, b, c)
+		k = k[12:]
+	}
+
+	// the lowest byte of c is reserved for the length
+	switch len(k) {
+	case 11:
+		c += uint32(k[10]) << 24
+		fallthrough
+	case 10:
+		c += uint32(k[9]) << 16
+		fallthrough
+	case 9:
+		c += uint32(k[8]) << 8
+		fallthrough
+	case 8:
+		b += uint32(k[7]) << 24
+		fallthrough
+	case 7:
+		b += uint32(k[6]) << 16
+		fallthrough
+	case 6:
+		b += uint32(k[5]) << 8
+		fallthrough
+	case 5:
+		b += uint32(k[4])
+		fallthrough
+	case 4:
+		a += uint32(k[3]) << 24
+		fallthrough
+	case 3:
+		a += uint32(k[2]) << 16
+		fallthrough
+	case 2:
+		a += uint32(k[1]) << 8
+		fallthrough
+	case 1:
+		a += uint32(k[0])
+	}
+
+	return final(a, b, c)
+}
+
+func hashUint32(k uint32) uint32 {
+	var a, b, c uint32
+	a = 0x9e3779b9 + 4 + 3923095
+	b, c = a, a
+	a += k
+
+	return final(a, b, c)
+}
+
+// hashInt8 matches PostgreSQL hashint8, which is compatible
+// with hashint4 for values in int4 range
+func hashInt8(val int64) uint32 {
+	lohalf := uint32(val)
+	hihalf := uint32(val >> 32)
+
+	if val >= 0 {
+		lohalf ^= hihalf
+	} else {
+		lohalf ^= ^hihalf
+	}
+
+	return hashUint32(lohalf)
+}
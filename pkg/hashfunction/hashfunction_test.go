@@ -0,0 +1,120 @@
+package hashfunction
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestApplyHashFunction(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   string
+		val  string
+		want string
+	}{
+		{name: "default is identity", fn: "", val: "abc", want: "abc"},
+		{name: "identity", fn: HashFunctionIdent, val: "123", want: "123"},
+		{name: "murmur3 of empty key", fn: HashFunctionMurmur3, val: "", want: "0"},
+		{name: "murmur3", fn: HashFunctionMurmur3, val: "hello", want: "613153351"},
+		{name: "murmur3 of long key", fn: HashFunctionMurmur3, val: "The quick brown fox jumps over the lazy dog", want: "776992547"},
+		// values of PostgreSQL hashint8 and hashtext, as unsigned integers
+		{name: "hashint8 of zero", fn: HashFunctionHashInt8, val: "0", want: "4022255791"},
+		{name: "hashint8", fn: HashFunctionHashInt8, val: "1", want: "2389907270"},
+		{name: "hashint8 of negative key", fn: HashFunctionHashInt8, val: "-1", want: "385747274"},
+		{name: "hashint8 of key outside int4 range", fn: HashFunctionHashInt8, val: "4294967296", want: "2389907270"},
+		{name: "hashtext of empty key", fn: HashFunctionHashText, val: "", want: "2817148525"},
+		{name: "hashtext", fn: HashFunctionHashText, val: "a", want: "1075015857"},
+		{name: "hashtext of block-sized key", fn: HashFunctionHashText, val: "sharding key", want: "2733876658"},
+		{name: "hashtext of long key", fn: HashFunctionHashText, val: "The quick brown fox jumps over the lazy dog", want: "3467537095"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyHashFunction(tt.fn, []byte(tt.val))
+			if err != nil {
+				t.Fatalf("ApplyHashFunction(%q, %q): %v", tt.fn, tt.val, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ApplyHashFunction(%q, %q) = %s, want %s", tt.fn, tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyHashFunctionErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		fn   string
+		val  string
+	}{
+		{name: "unknown function", fn: "md5", val: "1"},
+		{name: "hashint8 of text", fn: HashFunctionHashInt8, val: "abc"},
+		{name: "hashint8 out of range", fn: HashFunctionHashInt8, val: "9223372036854775808"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyHashFunction(tt.fn, []byte(tt.val)); err == nil {
+				t.Errorf("ApplyHashFunction(%q, %q) succeeded, want error", tt.fn, tt.val)
+			}
+		})
+	}
+}
+
+func TestHashValuesAreUint32(t *testing.T) {
+	for _, fn := range []string{HashFunctionMurmur3, HashFunctionHashInt8, HashFunctionHashText} {
+		for _, val := range []string{"0", "1", "-1", "42", "9223372036854775807", "-9223372036854775808"} {
+			got, err := ApplyHashFunction(fn, []byte(val))
+			if err != nil {
+				t.Fatalf("ApplyHashFunction(%q, %q): %v", fn, val, err)
+			}
+			if _, err := strconv.ParseUint(string(got), 10, 32); err != nil {
+				t.Errorf("ApplyHashFunction(%q, %q) = %s, want decimal uint32", fn, val, got)
+			}
+
+			again, _ := ApplyHashFunction(fn, []byte(val))
+			if string(again) != string(got) {
+				t.Errorf("ApplyHashFunction(%q, %q) is not deterministic: %s, %s", fn, val, got, again)
+			}
+		}
+	}
+}
+
+func TestHashInt8CompatibleWithHashInt4(t *testing.T) {
+	// PostgreSQL hashint8 hashes values of int4 range as hashint4 does
+	for _, val := range []int64{0, 1, 42, 2147483647} {
+		if got, want := hashInt8(val), hashUint32(uint32(val)); got != want {
+			t.Errorf("hashInt8(%d) = %d, want %d", val, got, want)
+		}
+	}
+
+	for _, val := range []int64{-1, -42, -2147483648} {
+		if got, want := hashInt8(val), hashUint32(uint32(int32(val))); got != want {
+			t.Errorf("hashInt8(%d) = %d, want %d", val, got, want)
+		}
+	}
+}
+
+func TestHashTextDependsOnEveryByte(t *testing.T) {
+	// keys of every tail length of lookup3 block
+	key := []byte("abcdefghijklmnopqrstuvwx")
+
+	for n := 1; n <= len(key); n++ {
+		orig := hashBytes(key[:n])
+
+		changed := append([]byte{}, key[:n]...)
+		changed[n-1]++
+
+		if hashBytes(changed) == orig {
+			t.Errorf("hash of %q equals hash of %q", changed, key[:n])
+		}
+	}
+}
+
+func TestValidateHashFunction(t *testing.T) {
+	for _, fn := range []string{"", HashFunctionIdent, HashFunctionMurmur3, HashFunctionHashInt8, HashFunctionHashText} {
+		if err := ValidateHashFunction(fn); err != nil {
+			t.Errorf("ValidateHashFunction(%q): %v", fn, err)
+		}
+	}
+
+	if err := ValidateHashFunction("crc32"); err == nil {
+		t.Errorf("ValidateHashFunction(%q) succeeded, want error", "crc32")
+	}
+}
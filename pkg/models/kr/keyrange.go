@@ -1,9 +1,13 @@
 package kr
 
 import (
+	"bytes"
+	"strings"
+
 	"github.com/pg-sharding/spqr/qdb"
 	proto "github.com/pg-sharding/spqr/router/protos"
 	spqrparser "github.com/pg-sharding/spqr/yacc/console"
+	"golang.org/x/xerrors"
 )
 
 type KeyRangeBound []byte
@@ -18,14 +22,53 @@ type KeyRange struct {
 	UpperBound []byte
 	ShardID    string
 	ID         string
+	KeyType    string
+}
+
+// TupleDelimiter separates components of composite sharding key,
+// which are encoded in sharding rule columns order, e.g. 1,100
+const TupleDelimiter = ','
+
+func TupleKey(components [][]byte) []byte {
+	return bytes.Join(components, []byte{TupleDelimiter})
+}
+
+func keyTypeOrDefault(keyType string) string {
+	if keyType == "" {
+		return DefaultKeyType
+	}
+	return keyType
 }
 
-func CmpRanges(kr []byte, other []byte) bool {
-	if len(kr) == len(other) {
-		return string(kr) <= string(other)
+// Contains checks if key of sharding rule with given number
+// of columns belongs to [LowerBound, UpperBound)
+func (kr *KeyRange) Contains(columns int, key []byte) (bool, error) {
+	lres, err := CmpKeys(kr.KeyType, columns, kr.LowerBound, key)
+	if err != nil {
+		return false, err
+	}
+	ures, err := CmpKeys(kr.KeyType, columns, key, kr.UpperBound)
+	if err != nil {
+		return false, err
+	}
+	return lres <= 0 && ures < 0, nil
+}
+
+// Validate checks key type and bounds of key range
+func (kr *KeyRange) Validate() error {
+	if err := ValidateKeyType(kr.KeyType); err != nil {
+		return err
+	}
+
+	res, err := CmpKeys(kr.KeyType, KeyColumns(kr.LowerBound, kr.UpperBound), kr.LowerBound, kr.UpperBound)
+	if err != nil {
+		return err
+	}
+	if res >= 0 {
+		return xerrors.Errorf("key range %v lower bound %s should be less than upper bound %s", kr.ID, kr.LowerBound, kr.UpperBound)
 	}
 
-	return len(kr) <= len(other)
+	return nil
 }
 
 func KeyRangeFromDB(kr *qdb.KeyRange) *KeyRange {
@@ -34,6 +77,7 @@ func KeyRangeFromDB(kr *qdb.KeyRange) *KeyRange {
 		UpperBound: kr.UpperBound,
 		ShardID:    kr.ShardID,
 		ID:         kr.KeyRangeID,
+		KeyType:    keyTypeOrDefault(kr.KeyType),
 	}
 }
 
@@ -46,6 +90,7 @@ func KeyRangeFromSQL(kr *spqrparser.AddKeyRange) *KeyRange {
 		UpperBound: kr.UpperBound,
 		ShardID:    kr.ShardID,
 		ID:         kr.KeyRangeID,
+		KeyType:    keyTypeOrDefault(strings.ToLower(kr.KeyType)),
 	}
 }
 
@@ -55,10 +100,11 @@ func KeyRangeFromProto(kr *proto.KeyRangeInfo) *KeyRange {
 		return nil
 	}
 	return &KeyRange{
-		LowerBound: []byte(kr.KeyRange.LowerBound),
-		UpperBound: []byte(kr.KeyRange.UpperBound),
+		LowerBound: kr.KeyRange.LowerBound,
+		UpperBound: kr.KeyRange.UpperBound,
 		ShardID:    kr.ShardId,
 		ID:         kr.Krid,
+		KeyType:    keyTypeOrDefault(kr.KeyType),
 	}
 }
 
@@ -68,16 +114,18 @@ func (kr *KeyRange) ToSQL() *qdb.KeyRange {
 		UpperBound: kr.UpperBound,
 		ShardID:    kr.ShardID,
 		KeyRangeID: kr.ID,
+		KeyType:    kr.KeyType,
 	}
 }
 
 func (kr *KeyRange) ToProto() *proto.KeyRangeInfo {
 	return &proto.KeyRangeInfo{
 		KeyRange: &proto.KeyRange{
-			LowerBound: string(kr.LowerBound),
-			UpperBound: string(kr.UpperBound),
+			LowerBound: kr.LowerBound,
+			UpperBound: kr.UpperBound,
 		},
 		ShardId: kr.ShardID,
 		Krid:    kr.ID,
+		KeyType: kr.KeyType,
 	}
 }
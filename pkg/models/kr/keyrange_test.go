@@ -0,0 +1,116 @@
+package kr
+
+import "testing"
+
+func TestKeyRangeContains(t *testing.T) {
+	keyRange := &KeyRange{
+		ID:         "kr1",
+		LowerBound: []byte("1"),
+		UpperBound: []byte("11"),
+		KeyType:    KeyTypeInteger,
+	}
+
+	for _, tt := range []struct {
+		key  string
+		want bool
+	}{
+		{key: "0", want: false},
+		{key: "1", want: true},
+		{key: "5", want: true},
+		{key: "10", want: true},
+		{key: "11", want: false},
+		{key: "100", want: false},
+	} {
+		got, err := keyRange.Contains(1, []byte(tt.key))
+		if err != nil {
+			t.Fatalf("Contains(%q): %v", tt.key, err)
+		}
+		if got != tt.want {
+			t.Errorf("[1, 11) Contains(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	if _, err := keyRange.Contains(1, []byte("abc")); err == nil {
+		t.Errorf("Contains(%q) succeeded, want error", "abc")
+	}
+}
+
+func TestKeyRangeContainsCompositeKey(t *testing.T) {
+	keyRange := &KeyRange{
+		ID:         "kr1",
+		LowerBound: []byte("1,100"),
+		UpperBound: []byte("2,0"),
+		KeyType:    KeyTypeInteger,
+	}
+
+	for _, tt := range []struct {
+		components []string
+		want       bool
+	}{
+		{components: []string{"1", "99"}, want: false},
+		{components: []string{"1", "100"}, want: true},
+		{components: []string{"1", "1000"}, want: true},
+		{components: []string{"2", "0"}, want: false},
+	} {
+		var components [][]byte
+		for _, c := range tt.components {
+			components = append(components, []byte(c))
+		}
+		key := TupleKey(components)
+
+		got, err := keyRange.Contains(2, key)
+		if err != nil {
+			t.Fatalf("Contains(%q): %v", key, err)
+		}
+		if got != tt.want {
+			t.Errorf("[1,100, 2,0) Contains(%q) = %v, want %v", key, got, tt.want)
+		}
+	}
+}
+
+func TestKeyRangeContainsKeyWithDelimiter(t *testing.T) {
+	keyRange := &KeyRange{
+		ID:         "kr1",
+		LowerBound: []byte("a+"),
+		UpperBound: []byte("b"),
+		KeyType:    KeyTypeVarchar,
+	}
+
+	// key of single column is not split into components
+	if got, err := keyRange.Contains(1, []byte("a,b")); err != nil || !got {
+		t.Errorf("[a+, b) Contains(%q) = %v, %v, want true", "a,b", got, err)
+	}
+	if got, err := keyRange.Contains(2, []byte("a,b")); err != nil || got {
+		t.Errorf("[a+, b) Contains(%q) of composite key = %v, %v, want false", "a,b", got, err)
+	}
+}
+
+func TestKeyRangeValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		lower   string
+		upper   string
+		keyType string
+		valid   bool
+	}{
+		{name: "valid", lower: "1", upper: "11", keyType: KeyTypeInteger, valid: true},
+		{name: "empty", lower: "11", upper: "11", keyType: KeyTypeInteger},
+		{name: "reversed", lower: "11", upper: "1", keyType: KeyTypeInteger},
+		{name: "numeric order of integers", lower: "9", upper: "10", keyType: KeyTypeInteger, valid: true},
+		{name: "bytewise order of varchar", lower: "9", upper: "10", keyType: KeyTypeVarchar},
+		{name: "unknown key type", lower: "1", upper: "2", keyType: "float"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRange := &KeyRange{
+				ID:         "kr1",
+				LowerBound: []byte(tt.lower),
+				UpperBound: []byte(tt.upper),
+				KeyType:    tt.keyType,
+			}
+
+			if err := keyRange.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() of [%s, %s) of type %s = %v, want valid %v", tt.lower, tt.upper, tt.keyType, err, tt.valid)
+			}
+		})
+	}
+}
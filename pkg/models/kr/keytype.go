@@ -0,0 +1,138 @@
+package kr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Key types define order of key range bounds and sharding keys
+const (
+	// KeyTypeInteger orders keys numerically
+	KeyTypeInteger = "integer"
+	// KeyTypeUUID orders keys as PostgreSQL uuid type does
+	KeyTypeUUID = "uuid"
+	// KeyTypeVarchar orders keys bytewise, as "C" collation does
+	KeyTypeVarchar = "varchar"
+)
+
+// DefaultKeyType is used for key ranges, created without explicit type
+const DefaultKeyType = KeyTypeInteger
+
+func ValidateKeyType(keyType string) error {
+	switch keyType {
+	case KeyTypeInteger, KeyTypeUUID, KeyTypeVarchar:
+		return nil
+	default:
+		return xerrors.Errorf("unknown key type %v", keyType)
+	}
+}
+
+func parseInteger(key []byte) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(string(key), 10)
+	if !ok {
+		return nil, xerrors.Errorf("invalid input syntax for type integer: %q", key)
+	}
+	return v, nil
+}
+
+func parseUUID(key []byte) ([]byte, error) {
+	s := strings.Trim(string(key), "{}")
+	s = strings.ReplaceAll(s, "-", "")
+
+	if len(s) != 32 {
+		return nil, xerrors.Errorf("invalid input syntax for type uuid: %q", key)
+	}
+
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid input syntax for type uuid: %q", key)
+	}
+	return v, nil
+}
+
+func cmpComponents(keyType string, lhs []byte, rhs []byte) (int, error) {
+	switch keyType {
+	case KeyTypeInteger:
+		l, err := parseInteger(lhs)
+		if err != nil {
+			return 0, err
+		}
+		r, err := parseInteger(rhs)
+		if err != nil {
+			return 0, err
+		}
+		return l.Cmp(r), nil
+	case KeyTypeUUID:
+		l, err := parseUUID(lhs)
+		if err != nil {
+			return 0, err
+		}
+		r, err := parseUUID(rhs)
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(l, r), nil
+	case KeyTypeVarchar:
+		return bytes.Compare(lhs, rhs), nil
+	default:
+		return 0, xerrors.Errorf("unknown key type %v", keyType)
+	}
+}
+
+// splitKey splits key of sharding rule with given number of columns into components.
+// Key of single column rule is a single component, even if it contains delimiter
+func splitKey(columns int, key []byte) [][]byte {
+	if columns <= 1 {
+		return [][]byte{key}
+	}
+	return bytes.SplitN(key, []byte{TupleDelimiter}, columns)
+}
+
+// KeyColumns returns number of columns of composite key bounds. Key ranges are not
+// bound to sharding rules, so bound is considered composite, if it contains delimiter
+func KeyColumns(bounds ...[]byte) int {
+	columns := 1
+	for _, bound := range bounds {
+		if n := bytes.Count(bound, []byte{TupleDelimiter}) + 1; n > columns {
+			columns = n
+		}
+	}
+	return columns
+}
+
+// CmpKeys compares keys of sharding rule with given number of columns
+// component by component according to key type.
+// The result is 0 if lhs == rhs, -1 if lhs < rhs, and +1 if lhs > rhs
+func CmpKeys(keyType string, columns int, lhs []byte, rhs []byte) (int, error) {
+	lcomps := splitKey(columns, lhs)
+	rcomps := splitKey(columns, rhs)
+
+	for i := 0; i < len(lcomps) && i < len(rcomps); i++ {
+		res, err := cmpComponents(keyType, lcomps[i], rcomps[i])
+		if err != nil {
+			return 0, err
+		}
+		if res != 0 {
+			return res, nil
+		}
+	}
+
+	switch {
+	case len(lcomps) < len(rcomps):
+		return -1, nil
+	case len(lcomps) > len(rcomps):
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// ValidateBound checks that every bound component is a valid value of key type
+func ValidateBound(keyType string, bound []byte) error {
+	_, err := CmpKeys(keyType, KeyColumns(bound), bound, bound)
+	return err
+}
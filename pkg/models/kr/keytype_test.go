@@ -0,0 +1,78 @@
+package kr
+
+import "testing"
+
+func TestCmpKeys(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		keyType string
+		columns int
+		lhs     string
+		rhs     string
+		want    int
+	}{
+		{name: "integers are ordered numerically", keyType: KeyTypeInteger, columns: 1, lhs: "9", rhs: "10", want: -1},
+		{name: "equal integers", keyType: KeyTypeInteger, columns: 1, lhs: "10", rhs: "10", want: 0},
+		{name: "negative integer", keyType: KeyTypeInteger, columns: 1, lhs: "-5", rhs: "3", want: -1},
+		{name: "integer beyond int64", keyType: KeyTypeInteger, columns: 1, lhs: "18446744073709551616", rhs: "9223372036854775807", want: 1},
+		{name: "varchar is ordered bytewise", keyType: KeyTypeVarchar, columns: 1, lhs: "9", rhs: "10", want: 1},
+		{name: "varchar prefix", keyType: KeyTypeVarchar, columns: 1, lhs: "ab", rhs: "abc", want: -1},
+		{name: "uuid", keyType: KeyTypeUUID, columns: 1, lhs: "00000000-0000-0000-0000-000000000001", rhs: "00000000-0000-0000-0000-000000000002", want: -1},
+		{name: "uuid in braces without dashes", keyType: KeyTypeUUID, columns: 1, lhs: "{a0eebc999c0b4ef8bb6d6bb9bd380a11}", rhs: "A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11", want: 0},
+		{name: "composite key by first component", keyType: KeyTypeInteger, columns: 2, lhs: "2,1", rhs: "10,0", want: -1},
+		{name: "composite key by second component", keyType: KeyTypeInteger, columns: 2, lhs: "2,10", rhs: "2,9", want: 1},
+		{name: "shorter composite key", keyType: KeyTypeInteger, columns: 2, lhs: "2", rhs: "2,0", want: -1},
+		{name: "single column key with delimiter", keyType: KeyTypeVarchar, columns: 1, lhs: "a,b", rhs: "a+", want: 1},
+		{name: "composite key with delimiter", keyType: KeyTypeVarchar, columns: 2, lhs: "a,b", rhs: "a+", want: -1},
+		{name: "delimiter in last component", keyType: KeyTypeVarchar, columns: 2, lhs: "a,b,c", rhs: "a,b+", want: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CmpKeys(tt.keyType, tt.columns, []byte(tt.lhs), []byte(tt.rhs))
+			if err != nil {
+				t.Fatalf("CmpKeys(%q, %q, %q): %v", tt.keyType, tt.lhs, tt.rhs, err)
+			}
+			if got != tt.want {
+				t.Errorf("CmpKeys(%q, %q, %q) = %d, want %d", tt.keyType, tt.lhs, tt.rhs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCmpKeysErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		keyType string
+		lhs     string
+		rhs     string
+	}{
+		{name: "text as integer", keyType: KeyTypeInteger, lhs: "abc", rhs: "1"},
+		{name: "invalid uuid", keyType: KeyTypeUUID, lhs: "123", rhs: "00000000-0000-0000-0000-000000000001"},
+		{name: "unknown key type", keyType: "float", lhs: "1", rhs: "2"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CmpKeys(tt.keyType, 1, []byte(tt.lhs), []byte(tt.rhs)); err == nil {
+				t.Errorf("CmpKeys(%q, %q, %q) succeeded, want error", tt.keyType, tt.lhs, tt.rhs)
+			}
+		})
+	}
+}
+
+func TestKeyColumns(t *testing.T) {
+	for _, tt := range []struct {
+		bounds []string
+		want   int
+	}{
+		{bounds: []string{"1", "11"}, want: 1},
+		{bounds: []string{"1,100", "2"}, want: 2},
+		{bounds: []string{"1", "2,0,0"}, want: 3},
+	} {
+		var bounds [][]byte
+		for _, bound := range tt.bounds {
+			bounds = append(bounds, []byte(bound))
+		}
+
+		if got := KeyColumns(bounds...); got != tt.want {
+			t.Errorf("KeyColumns(%q) = %d, want %d", tt.bounds, got, tt.want)
+		}
+	}
+}
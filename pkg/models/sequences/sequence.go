@@ -0,0 +1,33 @@
+package sequences
+
+import (
+	proto "github.com/pg-sharding/spqr/router/protos"
+)
+
+// Range is block of sequence values [First, First + Count),
+// allocated to router
+type Range struct {
+	Name  string
+	First int64
+	Count int64
+}
+
+func NewRange(name string, first int64, count int64) *Range {
+	return &Range{
+		Name:  name,
+		First: first,
+		Count: count,
+	}
+}
+
+func RangeFromProto(r *proto.SequenceRange) *Range {
+	return NewRange(r.Name, r.First, r.Count)
+}
+
+func (r *Range) ToProto() *proto.SequenceRange {
+	return &proto.SequenceRange{
+		Name:  r.Name,
+		First: r.First,
+		Count: r.Count,
+	}
+}
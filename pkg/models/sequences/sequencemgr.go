@@ -0,0 +1,9 @@
+package sequences
+
+import "context"
+
+type SequenceMgr interface {
+	// NextRange allocates block of count values of sequence,
+	// which are never allocated again
+	NextRange(ctx context.Context, name string, count int64) (*Range, error)
+}
@@ -1,17 +1,97 @@
 package shrule
 
+import (
+	"strings"
+
+	"github.com/pg-sharding/spqr/qdb"
+	proto "github.com/pg-sharding/spqr/router/protos"
+	spqrparser "github.com/pg-sharding/spqr/yacc/console"
+)
+
 type ShardingRule struct {
-	colunms []string
+	id           string
+	tableName    string
+	colunms      []string
+	hashFunction string
 }
 
 // local table sharding rule -> route to world
 
-func NewShardingRule(cols []string) *ShardingRule {
+// composite rule columns order defines order of sharding key components.
+// rule with empty table name applies to columns of any relation.
+// hash function is applied to every key component before key range matching
+func NewShardingRule(id string, tableName string, cols []string, hashFunction string) *ShardingRule {
 	return &ShardingRule{
-		colunms: cols,
+		id:           id,
+		tableName:    tableName,
+		colunms:      cols,
+		hashFunction: hashFunction,
 	}
 }
 
+func (s *ShardingRule) ID() string {
+	return s.id
+}
+
+func (s *ShardingRule) TableName() string {
+	return s.tableName
+}
+
 func (s *ShardingRule) Columns() []string {
 	return s.colunms
 }
+
+func (s *ShardingRule) HashFunction() string {
+	return s.hashFunction
+}
+
+func ShardingRuleFromSQL(rule *spqrparser.ShardingRule) *ShardingRule {
+	if rule == nil {
+		return nil
+	}
+
+	tableName := rule.TableName
+	cols := make([]string, 0, len(rule.Columns))
+
+	// columns may be qualified with table name: orders.customer_id
+	for _, col := range rule.Columns {
+		if i := strings.LastIndexByte(col, '.'); i != -1 {
+			if tableName == "" {
+				tableName = col[:i]
+			}
+			col = col[i+1:]
+		}
+		cols = append(cols, col)
+	}
+
+	return NewShardingRule(rule.ID, tableName, cols, rule.HashFunction)
+}
+
+func ShardingRuleFromDB(rule *qdb.ShardingRule) *ShardingRule {
+	return NewShardingRule(rule.ID, rule.TableName, rule.Columns, rule.HashFunction)
+}
+
+func ShardingRuleFromProto(rule *proto.ShardingRule) *ShardingRule {
+	if rule == nil {
+		return nil
+	}
+	return NewShardingRule(rule.Id, rule.TableName, rule.Columns, rule.HashFunction)
+}
+
+func (s *ShardingRule) ToSQL() *qdb.ShardingRule {
+	return &qdb.ShardingRule{
+		ID:           s.id,
+		TableName:    s.tableName,
+		Columns:      s.colunms,
+		HashFunction: s.hashFunction,
+	}
+}
+
+func (s *ShardingRule) ToProto() *proto.ShardingRule {
+	return &proto.ShardingRule{
+		Id:           s.id,
+		TableName:    s.tableName,
+		Columns:      s.colunms,
+		HashFunction: s.hashFunction,
+	}
+}
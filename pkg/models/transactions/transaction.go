@@ -0,0 +1,38 @@
+package transactions
+
+import (
+	"github.com/pg-sharding/spqr/qdb"
+)
+
+type Status string
+
+const Committed = Status("COMMITTED")
+const Aborted = Status("ABORTED")
+
+// Transaction is decision on distributed transaction, prepared on datashards
+// with global identifier GID. Prepared transaction without decision is aborted
+type Transaction struct {
+	GID    string
+	Shards []string
+	Status Status
+}
+
+func NewTransaction(gid string, shards []string, status Status) *Transaction {
+	return &Transaction{
+		GID:    gid,
+		Shards: shards,
+		Status: status,
+	}
+}
+
+func TransactionFromDB(tx *qdb.Transaction) *Transaction {
+	return NewTransaction(tx.GID, tx.Shards, Status(tx.Status))
+}
+
+func (tx *Transaction) ToSQL() *qdb.Transaction {
+	return &qdb.Transaction{
+		GID:    tx.GID,
+		Shards: tx.Shards,
+		Status: qdb.TransactionStatus(tx.Status),
+	}
+}
@@ -0,0 +1,10 @@
+package transactions
+
+import "context"
+
+type TransactionMgr interface {
+	// RecordTransaction records decision on transaction, unless it is
+	// already recorded, and returns recorded decision
+	RecordTransaction(ctx context.Context, tx *Transaction) (*Transaction, error)
+	DeleteTransaction(ctx context.Context, gid string) error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"path"
+	"strconv"
 	"sync"
 	"time"
 
@@ -66,6 +67,9 @@ type EtcdQDB struct {
 
 const keyRangesNamespace = "/keyranges"
 const routersRangesNamespace = "/routers"
+const shardingRulesNamespace = "/sharding_rules"
+const transactionsNamespace = "/transactions"
+const sequencesNamespace = "/sequences"
 
 func keyLockPath(key string) string {
 	return path.Join(key, "lock")
@@ -79,6 +83,18 @@ func routerNodePath(key string) string {
 	return path.Join(routersRangesNamespace, key)
 }
 
+func shardingRuleNodePath(key string) string {
+	return path.Join(shardingRulesNamespace, key)
+}
+
+func transactionNodePath(key string) string {
+	return path.Join(transactionsNamespace, key)
+}
+
+func sequenceNodePath(key string) string {
+	return path.Join(sequencesNamespace, key)
+}
+
 func (q *EtcdQDB) DropKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
 	resp, err := q.cli.Delete(ctx, keyRangeNodePath(keyRange.KeyRangeID))
 
@@ -112,6 +128,67 @@ func (q *EtcdQDB) Watch(krid string, status *qdb.KeyRangeStatus, notifyio chan<-
 	return nil
 }
 
+// watchRetryInterval is interval between attempts to establish lost watch
+const watchRetryInterval = time.Second
+
+func (q *EtcdQDB) WatchTopology(ctx context.Context, notifyio chan<- interface{}) error {
+	namespaces := []string{keyRangesNamespace, shardingRulesNamespace}
+
+	var mu sync.Mutex
+	established := map[string]bool{}
+
+	// watch state is notified under lock, so states of watches are not reordered
+	notifyState := func(namespace string, ok bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		established[namespace] = ok
+
+		state := &qdb.WatchState{Established: true}
+		for _, namespace := range namespaces {
+			state.Established = state.Established && established[namespace]
+		}
+
+		select {
+		case notifyio <- state:
+		case <-ctx.Done():
+		}
+	}
+
+	for _, namespace := range namespaces {
+		go func(namespace string) {
+			for ctx.Err() == nil {
+				wch := q.cli.Watch(clientv3.WithRequireLeader(ctx), namespace, clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+
+				for resp := range wch {
+					switch {
+					case resp.Err() != nil:
+						tracelog.ErrorLogger.PrintError(resp.Err())
+					case resp.Created:
+						notifyState(namespace, true)
+					default:
+						select {
+						case notifyio <- resp:
+						case <-ctx.Done():
+						}
+					}
+				}
+
+				// changes are missed, until watch is established again
+				tracelog.InfoLogger.Printf("watch of %s is lost", namespace)
+				notifyState(namespace, false)
+
+				select {
+				case <-time.After(watchRetryInterval):
+				case <-ctx.Done():
+				}
+			}
+		}(namespace)
+	}
+
+	return nil
+}
+
 const keyspace = "key_space"
 
 func NewEtcdQDB(addr string) (*EtcdQDB, error) {
@@ -163,7 +240,7 @@ func (q *EtcdQDB) Lock(ctx context.Context, keyRangeID string) (*qdb.KeyRange, e
 
 		defer mu.Unlock(ctx)
 
-		resp, err := q.cli.Get(ctx, keyLockPath(keyRangeID))
+		resp, err := q.cli.Get(ctx, keyLockPath(keyRangeNodePath(keyRangeID)))
 		if err != nil {
 			return nil, err
 		}
@@ -191,9 +268,12 @@ func (q *EtcdQDB) Lock(ctx context.Context, keyRangeID string) (*qdb.KeyRange, e
 	for {
 		select {
 		case <-timer.C:
-			if val, err := fetcher(ctx, sess, keyRangeID); err != nil {
+			val, err := fetcher(ctx, sess, keyRangeID)
+			if err == nil {
 				return val, nil
 			}
+			tracelog.InfoLogger.Printf("retry to lock key range %v: %v", keyRangeID, err)
+			timer.Reset(time.Second)
 		case <-fetchCtx.Done():
 			return nil, xerrors.Errorf("deadlines exceeded")
 		}
@@ -242,7 +322,7 @@ func (q *EtcdQDB) UnLock(ctx context.Context, keyRangeID string) error {
 
 		defer mu.Unlock(ctx)
 
-		resp, err := q.cli.Get(ctx, keyLockPath(keyRangeID))
+		resp, err := q.cli.Get(ctx, keyLockPath(keyRangeNodePath(keyRangeID)))
 		if err != nil {
 			return err
 		}
@@ -267,9 +347,7 @@ func (q *EtcdQDB) UnLock(ctx context.Context, keyRangeID string) error {
 	for {
 		select {
 		case <-timer.C:
-			if err := unlocker(ctx, sess, keyRangeID); err != nil {
-				return nil
-			}
+			return unlocker(ctx, sess, keyRangeID)
 		case <-fetchCtx.Done():
 			return xerrors.Errorf("deadlines exceeded")
 		}
@@ -292,6 +370,10 @@ func (q *EtcdQDB) AddKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error
 	return err
 }
 
+func (q *EtcdQDB) UpdateKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
+	return q.AddKeyRange(ctx, keyRange)
+}
+
 func (q *EtcdQDB) ListKeyRanges(ctx context.Context) ([]*qdb.KeyRange, error) {
 	resp, err := q.cli.Get(ctx, keyRangesNamespace, clientv3.WithPrefix())
 	if err != nil {
@@ -302,6 +384,11 @@ func (q *EtcdQDB) ListKeyRanges(ctx context.Context) ([]*qdb.KeyRange, error) {
 	var ret []*qdb.KeyRange
 
 	for _, e := range resp.Kvs {
+		if path.Dir(string(e.Key)) != keyRangesNamespace {
+			// lock of key range
+			continue
+		}
+
 		var kr qdb.KeyRange
 
 		if err := json.Unmarshal(e.Value, &kr); err != nil {
@@ -314,6 +401,132 @@ func (q *EtcdQDB) ListKeyRanges(ctx context.Context) ([]*qdb.KeyRange, error) {
 	return ret, nil
 }
 
+func (q *EtcdQDB) AddShardingRule(ctx context.Context, rule *qdb.ShardingRule) error {
+	rawShardingRule, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	resp, err := q.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(shardingRuleNodePath(rule.ID)), "=", 0)).
+		Then(clientv3.OpPut(shardingRuleNodePath(rule.ID), string(rawShardingRule))).
+		Commit()
+	if err != nil {
+		return err
+	}
+
+	if !resp.Succeeded {
+		return xerrors.Errorf("sharding rule %v already present in qdb", rule.ID)
+	}
+
+	tracelog.InfoLogger.Printf("put resp %v", resp)
+	return nil
+}
+
+func (q *EtcdQDB) ListShardingRules(ctx context.Context) ([]*qdb.ShardingRule, error) {
+	resp, err := q.cli.Get(ctx, shardingRulesNamespace, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	tracelog.InfoLogger.Printf("got resp %v", resp)
+	var ret []*qdb.ShardingRule
+
+	for _, e := range resp.Kvs {
+		var rule qdb.ShardingRule
+
+		if err := json.Unmarshal(e.Value, &rule); err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, &rule)
+	}
+
+	return ret, nil
+}
+
+func (q *EtcdQDB) RecordTransaction(ctx context.Context, tx *qdb.Transaction) (*qdb.Transaction, error) {
+	rawTransaction, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodePath := transactionNodePath(tx.GID)
+
+	resp, err := q.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(nodePath), "=", 0)).
+		Then(clientv3.OpPut(nodePath, string(rawTransaction))).
+		Else(clientv3.OpGet(nodePath)).
+		Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	tracelog.InfoLogger.Printf("put resp %v", resp)
+
+	if resp.Succeeded {
+		return tx, nil
+	}
+
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) != 1 {
+		return nil, xerrors.Errorf("failed to fetch transaction %v", tx.GID)
+	}
+
+	var recorded qdb.Transaction
+	if err := json.Unmarshal(kvs[0].Value, &recorded); err != nil {
+		return nil, err
+	}
+
+	return &recorded, nil
+}
+
+func (q *EtcdQDB) DeleteTransaction(ctx context.Context, gid string) error {
+	resp, err := q.cli.Delete(ctx, transactionNodePath(gid))
+
+	tracelog.InfoLogger.Printf("delete resp %v", resp)
+	return err
+}
+
+func (q *EtcdQDB) AllocateSequenceRange(ctx context.Context, name string, count int64) (int64, error) {
+	nodePath := sequenceNodePath(name)
+
+	for {
+		resp, err := q.cli.Get(ctx, nodePath)
+		if err != nil {
+			return 0, err
+		}
+
+		var mark int64
+		var cmp clientv3.Cmp
+
+		switch len(resp.Kvs) {
+		case 0:
+			cmp = clientv3.Compare(clientv3.CreateRevision(nodePath), "=", 0)
+		case 1:
+			if mark, err = strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64); err != nil {
+				return 0, err
+			}
+			cmp = clientv3.Compare(clientv3.ModRevision(nodePath), "=", resp.Kvs[0].ModRevision)
+		default:
+			return 0, xerrors.Errorf("failed to fetch sequence %v", name)
+		}
+
+		// high-water mark is advanced only if nobody advanced it concurrently
+		txnResp, err := q.cli.Txn(ctx).
+			If(cmp).
+			Then(clientv3.OpPut(nodePath, strconv.FormatInt(mark+count, 10))).
+			Commit()
+		if err != nil {
+			return 0, err
+		}
+
+		if txnResp.Succeeded {
+			return mark + 1, nil
+		}
+	}
+}
+
 func (q *EtcdQDB) Check(ctx context.Context, kr *qdb.KeyRange) bool {
 	return true
 }
@@ -60,14 +60,21 @@ func (wg *WaitPool) Publish(msg interface{}) {
 	wg.publishCh <- msg
 }
 
+// TransactionError is returned, when decision on distributed transaction is recorded in memory qdb
+var TransactionError = xerrors.New("decisions on distributed transactions are not kept in memory qdb")
+
+// SequenceError is returned, when sequence range is allocated in memory qdb
+var SequenceError = xerrors.New("sequence values are not allocated in memory qdb")
+
 type QrouterDBMem struct {
 	qdb.QrouterDB
 
 	mu   sync.Mutex
 	txmu sync.Mutex
 
-	freq map[string]int
-	krs  map[string]*qdb.KeyRange
+	freq    map[string]int
+	krs     map[string]*qdb.KeyRange
+	shrules []*qdb.ShardingRule
 
 	krWaiters map[string]*WaitPool
 }
@@ -76,6 +83,11 @@ func (q *QrouterDBMem) Watch(krid string, status *qdb.KeyRangeStatus, notifyio c
 	return q.krWaiters[krid].Subscribe(status, notifyio)
 }
 
+// WatchTopology never notifies, as topology of in-memory qdb is changed only by its owner
+func (q *QrouterDBMem) WatchTopology(_ context.Context, _ chan<- interface{}) error {
+	return nil
+}
+
 func (q *QrouterDBMem) AddKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -107,6 +119,32 @@ func (q *QrouterDBMem) Check(_ context.Context, kr *qdb.KeyRange) bool {
 	return !ok
 }
 
+func (q *QrouterDBMem) AddShardingRule(_ context.Context, rule *qdb.ShardingRule) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, r := range q.shrules {
+		if r.ID == rule.ID {
+			return xerrors.Errorf("sharding rule %v already present in qdb", rule.ID)
+		}
+	}
+
+	q.shrules = append(q.shrules, rule)
+
+	return nil
+}
+
+func (q *QrouterDBMem) ListShardingRules(_ context.Context) ([]*qdb.ShardingRule, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// rules are matched in order of definition
+	ret := make([]*qdb.ShardingRule, len(q.shrules))
+	copy(ret, q.shrules)
+
+	return ret, nil
+}
+
 func NewQrouterDBMem() (*QrouterDBMem, error) {
 	return &QrouterDBMem{
 		freq:      map[string]int{},
@@ -156,4 +194,20 @@ func (q *QrouterDBMem) ListKeyRanges(_ context.Context) ([]*qdb.KeyRange, error)
 	return ret, nil
 }
 
+// RecordTransaction fails, as decision, kept in memory, is lost on router
+// failure and is not seen by other routers
+func (q *QrouterDBMem) RecordTransaction(_ context.Context, tx *qdb.Transaction) (*qdb.Transaction, error) {
+	return nil, xerrors.Errorf("failed to record distributed transaction %v: %w", tx.GID, TransactionError)
+}
+
+func (q *QrouterDBMem) DeleteTransaction(_ context.Context, gid string) error {
+	return xerrors.Errorf("failed to delete distributed transaction %v: %w", gid, TransactionError)
+}
+
+// AllocateSequenceRange fails, as high-water mark, kept in memory, is lost on router
+// restart and is not seen by other routers, so sequence values would repeat
+func (q *QrouterDBMem) AllocateSequenceRange(_ context.Context, name string, _ int64) (int64, error) {
+	return 0, xerrors.Errorf("failed to allocate range of sequence %v: %w", name, SequenceError)
+}
+
 var _ qdb.QrouterDB = &QrouterDBMem{}
@@ -10,9 +10,35 @@ type KeyRange struct {
 	UpperBound []byte `json:"to"`
 	ShardID    string `json:"shard_id"`
 	KeyRangeID string `json:"key_range_id"`
+	KeyType    string `json:"key_type"`
 }
+type ShardingRule struct {
+	ID           string   `json:"id"`
+	TableName    string   `json:"table"`
+	Columns      []string `json:"columns"`
+	HashFunction string   `json:"hash_function"`
+}
+
 type KeyRangeStatus string
 
+type TransactionStatus string
+
+const TxCommitted = TransactionStatus("COMMITTED")
+const TxAborted = TransactionStatus("ABORTED")
+
+// Transaction is decision on distributed transaction, prepared on datashards
+type Transaction struct {
+	GID    string            `json:"gid"`
+	Shards []string          `json:"shards"`
+	Status TransactionStatus `json:"status"`
+}
+
+// WatchState is notified by WatchTopology, when watch of topology is lost or established again.
+// Changes of topology may be missed, while watch is not established
+type WatchState struct {
+	Established bool
+}
+
 const KRLocked = KeyRangeStatus("LOCKED")
 const KRUnLocked = KeyRangeStatus("UNLOCKED")
 
@@ -2,8 +2,6 @@ package qdb
 
 import (
 	"context"
-
-	"github.com/pg-sharding/spqr/pkg/models/shrule"
 )
 
 type QrouterDB interface {
@@ -20,8 +18,22 @@ type QrouterDB interface {
 	Check(ctx context.Context, kr *KeyRange) bool
 
 	Watch(krid string, status *KeyRangeStatus, notifyio chan<- interface{}) error
+	// WatchTopology notifies about every change of key ranges
+	// and sharding rules, until ctx is done. Lost watch is established again,
+	// and state of watch is notified with *WatchState
+	WatchTopology(ctx context.Context, notifyio chan<- interface{}) error
 
-	ListShardingRules(ctx context.Context) ([]*shrule.ShardingRule, error)
+	AddShardingRule(ctx context.Context, rule *ShardingRule) error
+	ListShardingRules(ctx context.Context) ([]*ShardingRule, error)
 
 	ListKeyRanges(ctx context.Context) ([]*KeyRange, error)
+
+	// RecordTransaction records decision on distributed transaction, unless
+	// decision is already recorded. Recorded decision is returned
+	RecordTransaction(ctx context.Context, tx *Transaction) (*Transaction, error)
+	DeleteTransaction(ctx context.Context, gid string) error
+
+	// AllocateSequenceRange advances high-water mark of sequence by count
+	// and returns first value of allocated range
+	AllocateSequenceRange(ctx context.Context, name string, count int64) (int64, error)
 }
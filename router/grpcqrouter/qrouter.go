@@ -19,7 +19,7 @@ type LocalQrouterServer struct {
 
 func (l *LocalQrouterServer) AddShardingRules(ctx context.Context, request *protos.AddShardingRuleRequest) (*protos.AddShardingRuleReply, error) {
 	for _, rule := range request.Rules {
-		err := l.qr.AddShardingRule(ctx, shrule.NewShardingRule(rule.Columns))
+		err := l.qr.AddShardingRule(ctx, shrule.ShardingRuleFromProto(rule))
 
 		if err != nil {
 			return nil, err
@@ -38,9 +38,7 @@ func (l *LocalQrouterServer) ListShardingRules(ctx context.Context, request *pro
 	var shardingRules []*protos.ShardingRule
 
 	for _, rule := range rules {
-		shardingRules = append(shardingRules, &protos.ShardingRule{
-			Columns: rule.Columns(),
-		})
+		shardingRules = append(shardingRules, rule.ToProto())
 	}
 
 	return &protos.ListShardingRuleReply{
@@ -113,6 +111,7 @@ func Register(server reflection.GRPCServer, qrouter qrouter.QueryRouter) {
 	}
 
 	protos.RegisterKeyRangeServiceServer(server, lqr)
+	protos.RegisterShardingRulesServiceServer(server, lqr)
 }
 
 var _ protos.KeyRangeServiceServer = &LocalQrouterServer{}
@@ -11,6 +11,7 @@ import (
 	"github.com/pg-sharding/spqr/pkg/client"
 	"github.com/pg-sharding/spqr/pkg/config"
 	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/router/pkg/route"
 	"github.com/pg-sharding/spqr/router/pkg/server"
 	"github.com/pkg/errors"
@@ -34,6 +35,8 @@ type RouterClient interface {
 	Rule() *config.FRRule
 
 	ProcQuery(query *pgproto3.Query) (byte, error)
+	ProcShardQueries(queries map[kr.ShardKey]*pgproto3.Query) (byte, error)
+	ProcMessages(msgs []pgproto3.FrontendMessage, replyCl bool) (byte, error)
 }
 
 type PsqlClient struct {
@@ -356,6 +359,24 @@ func (cl *PsqlClient) ProcQuery(query *pgproto3.Query) (byte, error) {
 		return 0, err
 	}
 
+	return cl.relayResponses()
+}
+
+// ProcShardQueries sends every shard its own query and waits for ReadyForQuery
+func (cl *PsqlClient) ProcShardQueries(queries map[kr.ShardKey]*pgproto3.Query) (byte, error) {
+
+	for shkey, query := range queries {
+		tracelog.InfoLogger.Printf("process query %s on datashard %v", query.String, shkey.Name)
+
+		if err := cl.server.SendShard(shkey, query); err != nil {
+			return 0, err
+		}
+	}
+
+	return cl.relayResponses()
+}
+
+func (cl *PsqlClient) relayResponses() (byte, error) {
 	for {
 		msg, err := cl.server.Receive()
 		tracelog.InfoLogger.Printf("recv msg from server %v %w", msg, err)
@@ -374,6 +395,71 @@ func (cl *PsqlClient) ProcQuery(query *pgproto3.Query) (byte, error) {
 			////tracelog.InfoLogger.Println(msg)
 			return 0, err
 		}
+
+		if _, ok := msg.(*pgproto3.CopyInResponse); ok {
+			if err := cl.relayCopyData(); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// relayCopyData proxies client COPY data to server as is, until client finishes COPY
+func (cl *PsqlClient) relayCopyData() error {
+	for {
+		msg, err := cl.Receive()
+		if err != nil {
+			return err
+		}
+
+		switch msg.(type) {
+		case *pgproto3.CopyData:
+			if err := cl.server.Send(msg); err != nil {
+				return err
+			}
+		case *pgproto3.CopyDone, *pgproto3.CopyFail:
+			return cl.server.Send(msg)
+		default:
+			// Flush and Sync are ignored during COPY
+		}
+	}
+}
+
+// ProcMessages sends msgs to server and waits for ReadyForQuery.
+// Server responses are proxied to client only if replyCl is set,
+// otherwise errors are logged and swallowed.
+func (cl *PsqlClient) ProcMessages(msgs []pgproto3.FrontendMessage, replyCl bool) (byte, error) {
+
+	tracelog.InfoLogger.Printf("process %d messages, reply client %v", len(msgs), replyCl)
+
+	for _, msg := range msgs {
+		if err := cl.server.Send(msg); err != nil {
+			return 0, err
+		}
+	}
+
+	for {
+		msg, err := cl.server.Receive()
+		if err != nil {
+			return 0, err
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.ReadyForQuery:
+			return v.TxStatus, nil
+		case *pgproto3.ErrorResponse:
+			if !replyCl {
+				tracelog.InfoLogger.Printf("server replied with error on silent relay: %s", v.Message)
+			}
+		}
+
+		if !replyCl {
+			continue
+		}
+
+		if err := cl.Send(msg); err != nil {
+			return 0, err
+		}
 	}
 }
 
@@ -416,9 +502,16 @@ func (cl *PsqlClient) DefaultReply() error {
 }
 
 func (cl *PsqlClient) ReplyErr(errmsg string) error {
+	return cl.ReplyErrWithCode("", errmsg)
+}
+
+// ReplyErrWithCode replies error with SQLSTATE code
+func (cl *PsqlClient) ReplyErrWithCode(code string, errmsg string) error {
 	for _, msg := range []pgproto3.BackendMessage{
 		&pgproto3.ErrorResponse{
-			Message: errmsg,
+			Severity: "ERROR",
+			Code:     code,
+			Message:  errmsg,
 		},
 		&pgproto3.ReadyForQuery{},
 	} {
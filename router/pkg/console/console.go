@@ -57,9 +57,13 @@ type TopoCntl interface {
 	shrule.ShardingRulesMgr
 	datashards.ShardsMgr
 	kr.KeyRangeMgr
+
+	// routing of explained query
+	Route(q string) (qrouter.RoutingState, error)
+	WorldShardsRoutes() []*qrouter.ShardRoute
 }
 
-var intf = func(qlogger qlog.Qlog, t TopoCntl, cli client.PSQLInteractor, ctx context.Context, cl client.Client, q string) error {
+var intf = func(qlogger qlog.Qlog, t TopoCntl, rr rrouter.RequestRouter, cli client.PSQLInteractor, ctx context.Context, cl client.Client, q string) error {
 
 	tstmt, err := spqrparser.Parse(q)
 	if err != nil {
@@ -87,12 +91,14 @@ var intf = func(qlogger qlog.Qlog, t TopoCntl, cli client.PSQLInteractor, ctx co
 			} else {
 				return cli.KeyRanges(krs, cl)
 			}
-		case spqrparser.ShowShardingColumns:
+		case spqrparser.ShowShardingColumns, spqrparser.ShowShardingRules:
 			rules, err := t.ListShardingRules(ctx)
 			if err != nil {
 				return err
 			}
 			return cli.ShardingRules(ctx, rules, cl)
+		case spqrparser.ShowHostsStr:
+			return cli.Hosts(ctx, rr.ListHosts(), cl)
 		default:
 			tracelog.InfoLogger.Printf("Unknown default %s", stmt.Cmd)
 
@@ -116,17 +122,26 @@ var intf = func(qlogger qlog.Qlog, t TopoCntl, cli client.PSQLInteractor, ctx co
 		}
 		return cli.LockKeyRange(ctx, stmt.KeyRangeID, cl)
 	case *spqrparser.ShardingColumn:
-		err := t.AddShardingRule(ctx, shrule.NewShardingRule([]string{stmt.ColName}))
+		rule := shrule.NewShardingRule(stmt.ColName, "", []string{stmt.ColName}, "")
+		err := t.AddShardingRule(ctx, rule)
 		if err != nil {
 			_ = qlogger.DumpQuery(ctx, config.RouterConfig().AutoConf, q)
 		}
-		return cli.AddShardingRule(ctx, shrule.NewShardingRule([]string{stmt.ColName}), cl)
+		return cli.AddShardingRule(ctx, rule, cl)
+	case *spqrparser.ShardingRule:
+		rule := shrule.ShardingRuleFromSQL(stmt)
+		if err := t.AddShardingRule(ctx, rule); err != nil {
+			return err
+		}
+		_ = qlogger.DumpQuery(ctx, config.RouterConfig().AutoConf, q)
+		return cli.AddShardingRule(ctx, rule, cl)
 	case *spqrparser.AddKeyRange:
-		err := t.AddKeyRange(ctx, kr.KeyRangeFromSQL(stmt))
-		if err != nil {
-			_ = qlogger.DumpQuery(ctx, config.RouterConfig().AutoConf, q)
+		keyRange := kr.KeyRangeFromSQL(stmt)
+		if err := t.AddKeyRange(ctx, keyRange); err != nil {
+			return err
 		}
-		return cli.AddKeyRange(ctx, kr.KeyRangeFromSQL(stmt), cl)
+		_ = qlogger.DumpQuery(ctx, config.RouterConfig().AutoConf, q)
+		return cli.AddKeyRange(ctx, keyRange, cl)
 	case *spqrparser.Shard:
 		err := t.AddDataShard(ctx, &datashards.DataShard{
 			ID: stmt.Name,
@@ -135,6 +150,13 @@ var intf = func(qlogger qlog.Qlog, t TopoCntl, cli client.PSQLInteractor, ctx co
 			_ = qlogger.DumpQuery(ctx, config.RouterConfig().AutoConf, q)
 		}
 		return err
+	case *spqrparser.Explain:
+		// query is explained as executed by console user
+		routes, err := explainRoute(t, stmt.Query, readPolicyOf(cl.Usr(), cl.DB()))
+		if err != nil {
+			return err
+		}
+		return cli.ExplainRoute(ctx, routes, cl)
 	case *spqrparser.Shutdown:
 		//t.stchan <- struct{}{}
 		return xerrors.New("not implemented")
@@ -149,7 +171,7 @@ var intf = func(qlogger qlog.Qlog, t TopoCntl, cli client.PSQLInteractor, ctx co
 }
 
 func (c *Local) ProcessQuery(ctx context.Context, q string, cl client.Client) error {
-	return intf(c.Qlog, c.Qrouter, client.PSQLInteractor{}, ctx, cl, q)
+	return intf(c.Qlog, c.Qrouter, c.RRouter, client.PSQLInteractor{}, ctx, cl, q)
 }
 
 const greeting = `
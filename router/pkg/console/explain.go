@@ -0,0 +1,89 @@
+package console
+
+import (
+	"github.com/pg-sharding/spqr/pkg/client"
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/router/pkg/qrouter"
+	"github.com/pg-sharding/spqr/router/pkg/rrouter"
+	"golang.org/x/xerrors"
+)
+
+// routing states of explained query
+const (
+	stateShardMatch = "shard match"
+	stateCopy       = "copy"
+	stateWorld      = "world"
+	stateSkip       = "skip"
+)
+
+// readPolicyOf returns read policy of frontend rule of user and database
+func readPolicyOf(usr, db string) config.ReadPolicy {
+	for _, rule := range config.RouterConfig().RouterConfig.FrontendRules {
+		if rule.RK.Usr == usr && rule.RK.DB == db {
+			return rule.ReadPolicy
+		}
+	}
+
+	return ""
+}
+
+// explainRoute routes query and describes every its datashard route.
+// Read-only queries are routed to primary or replicas by read policy
+func explainRoute(t TopoCntl, q string, policy config.ReadPolicy) ([]client.ExplainedRoute, error) {
+	state, err := t.Route(q)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, err := rrouter.ReadsOnPrimary(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	stmtType := qrouter.StatementType(q)
+
+	describe := func(state string, routes []*qrouter.ShardRoute) []client.ExplainedRoute {
+		if len(routes) == 0 {
+			return []client.ExplainedRoute{
+				{
+					StmtType: stmtType,
+					State:    state,
+				},
+			}
+		}
+
+		ret := make([]client.ExplainedRoute, 0, len(routes))
+
+		for _, route := range routes {
+			explained := client.ExplainedRoute{
+				StmtType: stmtType,
+				State:    state,
+				Shard:    route.Shkey.Name,
+				RW:       route.Shkey.RW || primary,
+			}
+			if route.Rule != nil {
+				explained.ShardingRule = route.Rule.ID()
+			}
+			if route.Matchedkr != nil {
+				explained.KeyRange = route.Matchedkr.ID
+			}
+
+			ret = append(ret, explained)
+		}
+
+		return ret
+	}
+
+	switch v := state.(type) {
+	case qrouter.ShardMatchState:
+		return describe(stateShardMatch, v.Routes), nil
+	case qrouter.CopyRouteState:
+		return describe(stateCopy, v.Routes), nil
+	case qrouter.WolrdRouteState:
+		return describe(stateWorld, t.WorldShardsRoutes()), nil
+	case qrouter.SkipRoutingState:
+		return describe(stateSkip, nil), nil
+	default:
+		return nil, xerrors.Errorf("unknown routing state %T", state)
+	}
+}
@@ -37,6 +37,22 @@ func Frontend(qr qrouter.QueryRouter, cl client.RouterClient, cmngr rrouter.Conn
 		switch q := msg.(type) {
 		case *pgproto3.Query:
 
+			if seq, ok := qrouter.NextValCall(q.String); ok {
+				// sequence value is allocated by router
+				if err := rst.ReplyNextVal(seq); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if name, value, ok := qrouter.ParseHintSetting(q.String); ok {
+				// routing hint is kept by router
+				if err := rst.SetHint(name, value); err != nil {
+					return err
+				}
+				continue
+			}
+
 			rst.AddQuery(*q)
 
 			// txactive == 0 || activeSh == nil
@@ -47,7 +63,18 @@ func Frontend(qr qrouter.QueryRouter, cl client.RouterClient, cmngr rrouter.Conn
 					_ = cl.Reply("ok")
 					continue
 				case qrouter.MatchShardError:
-					_ = cl.Reply(fmt.Sprintf("failed to match any datashard"))
+					rst.DiscardLastQuery()
+					_ = cl.ReplyErr(fmt.Sprintf("failed to match any datashard"))
+					continue
+				case qrouter.NoShardingKeyError, qrouter.SplitInsertError, qrouter.AggregateError,
+					qrouter.CopyFormatError, qrouter.CopyColumnsError, qrouter.SequenceError,
+					qrouter.HintError, qrouter.HintShardError, qrouter.HintRuleError, rrouter.ReplayTimeoutError:
+					rst.DiscardLastQuery()
+					_ = cl.ReplyErr(err.Error())
+					continue
+				case rrouter.MultiShardTxError:
+					rst.DiscardLastQuery()
+					_ = cl.ReplyErrWithCode(rrouter.MultiShardTxErrorCode, err.Error())
 					continue
 				case qrouter.ParseError:
 					_ = cl.ReplyNotice(fmt.Sprintf("skip executing this query, wait for next"))
@@ -94,6 +121,58 @@ func Frontend(qr qrouter.QueryRouter, cl client.RouterClient, cmngr rrouter.Conn
 
 			asynctracelog.Printf("active shards are %v", rst.ActiveShards)
 
+		case *pgproto3.Parse, *pgproto3.Bind, *pgproto3.Describe, *pgproto3.Execute, *pgproto3.Close:
+			rst.AddExtendedProtocMessage(q)
+		case *pgproto3.Flush:
+			// all buffered responses are sent to client on Sync
+		case *pgproto3.Sync:
+
+			if cmngr.ValidateReRoute(rst) {
+				switch err := rst.RerouteExtended(); err {
+				case rrouter.SkipQueryError, qrouter.ParseError:
+					_ = cl.ReplyNotice(fmt.Sprintf("skip executing this batch, wait for next"))
+					if err := rst.DeferExtended(); err != nil {
+						return err
+					}
+					continue
+				case qrouter.MatchShardError:
+					rst.FlushExtended()
+					_ = cl.ReplyErr(fmt.Sprintf("failed to match any datashard"))
+					continue
+				case qrouter.NoShardingKeyError, qrouter.SplitInsertError, qrouter.AggregateError,
+					qrouter.CopyFormatError, qrouter.CopyColumnsError, qrouter.SequenceError,
+					qrouter.HintError, qrouter.HintShardError, qrouter.HintRuleError, rrouter.ReplayTimeoutError:
+					rst.FlushExtended()
+					_ = cl.ReplyErr(err.Error())
+					continue
+				case rrouter.MultiShardBatchError:
+					rst.FlushExtended()
+					_ = cl.ReplyErr(err.Error())
+					continue
+				case rrouter.MultiShardTxError:
+					rst.FlushExtended()
+					_ = cl.ReplyErrWithCode(rrouter.MultiShardTxErrorCode, err.Error())
+					continue
+				case nil:
+
+				default:
+					tracelog.InfoLogger.Printf("encounter %v", err)
+					_ = rst.UnRouteWithError(nil, err)
+					return err
+				}
+			}
+
+			txst, err := rst.RelayExtendedStep()
+			if err != nil {
+				return err
+			}
+
+			if err := rst.CompleteRelay(txst); err != nil {
+				return err
+			}
+
+			asynctracelog.Printf("active shards are %v", rst.ActiveShards)
+
 		default:
 		}
 	}
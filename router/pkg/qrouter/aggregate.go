@@ -0,0 +1,108 @@
+package qrouter
+
+import (
+	"fmt"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/router/pkg/server"
+)
+
+// aggregateFuncs maps aggregates, which are combined from partial aggregates of datashards
+var aggregateFuncs = map[string]string{
+	"count": server.AggregateCount,
+	"sum":   server.AggregateSum,
+	"min":   server.AggregateMin,
+	"max":   server.AggregateMax,
+	"avg":   server.AggregateAvg,
+}
+
+// hiddenColumn adds expression to select list of query, as column, hidden from client
+func hiddenColumn(stmt *sqlparser.Select, plan *server.MergePlan, prefix string, expr sqlparser.Expr) int {
+	stmt.SelectExprs = append(stmt.SelectExprs, &sqlparser.AliasedExpr{
+		Expr: expr,
+		As:   sqlparser.NewColIdent(fmt.Sprintf("%s_%d", prefix, plan.HiddenColumns)),
+	})
+	plan.HiddenColumns++
+
+	return len(stmt.SelectExprs) - 1
+}
+
+// planAggregates rewrites select list of grouped query, so every datashard computes
+// partial aggregates for its groups. AVG is computed by router as SUM / COUNT.
+// Group keys, which are missing in select list, are added to it
+func planAggregates(stmt *sqlparser.Select, plan *server.MergePlan) bool {
+	// select list is modified, so keep original one intact
+	stmt.SelectExprs = append(sqlparser.SelectExprs{}, stmt.SelectExprs...)
+
+	groupKeys := map[string]bool{}
+	for _, gexpr := range stmt.GroupBy {
+		groupKeys[sqlparser.String(gexpr)] = false
+	}
+
+	// avg partial counts are added during the loop
+	columns := len(stmt.SelectExprs)
+
+	for i := 0; i < columns; i++ {
+		aexpr, ok := stmt.SelectExprs[i].(*sqlparser.AliasedExpr)
+		if !ok {
+			return false
+		}
+
+		if fexpr, ok := aexpr.Expr.(*sqlparser.FuncExpr); ok && fexpr.IsAggregate() {
+			fn, ok := aggregateFuncs[fexpr.Name.Lowered()]
+			if !ok || fexpr.Distinct || hasAggregates(fexpr.Exprs) {
+				return false
+			}
+
+			agg := server.Aggregate{
+				Column: i,
+				Func:   fn,
+			}
+
+			if fn == server.AggregateAvg {
+				as := aexpr.As
+				if as.IsEmpty() {
+					as = sqlparser.NewColIdent("avg")
+				}
+
+				stmt.SelectExprs[i] = &sqlparser.AliasedExpr{
+					Expr: &sqlparser.FuncExpr{Name: sqlparser.NewColIdent("sum"), Exprs: fexpr.Exprs},
+					As:   as,
+				}
+				agg.CountColumn = hiddenColumn(stmt, plan, "spqr_avg",
+					&sqlparser.FuncExpr{Name: sqlparser.NewColIdent("count"), Exprs: fexpr.Exprs})
+			}
+
+			plan.Aggregates = append(plan.Aggregates, agg)
+			continue
+		}
+
+		if hasAggregates(sqlparser.SelectExprs{aexpr}) {
+			// aggregate inside of expression
+			return false
+		}
+
+		key := sqlparser.String(aexpr.Expr)
+		if _, ok := groupKeys[key]; !ok {
+			if key = aexpr.As.String(); aexpr.As.IsEmpty() {
+				return false
+			}
+			if _, ok := groupKeys[key]; !ok {
+				return false
+			}
+		}
+
+		groupKeys[key] = true
+		plan.GroupColumns = append(plan.GroupColumns, i)
+	}
+
+	for _, gexpr := range stmt.GroupBy {
+		if groupKeys[sqlparser.String(gexpr)] {
+			continue
+		}
+
+		plan.GroupColumns = append(plan.GroupColumns, hiddenColumn(stmt, plan, "spqr_group", gexpr))
+	}
+
+	return true
+}
@@ -0,0 +1,550 @@
+package qrouter
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"golang.org/x/xerrors"
+)
+
+const (
+	CopyFormatText   = "text"
+	CopyFormatCSV    = "csv"
+	CopyFormatBinary = "binary"
+)
+
+// CopyStmt is COPY statement. SQL parser does not support it,
+// so statement is parsed by router itself
+type CopyStmt struct {
+	TableName string
+	Columns   []string
+	// query of COPY (query) TO, relation is not set then
+	Query string
+
+	// COPY ... FROM, otherwise COPY ... TO
+	From bool
+	// data is transferred via STDIN or STDOUT
+	Stdio bool
+
+	Format    string
+	Delimiter byte
+	Quote     byte
+	Escape    byte
+	Null      string
+	Header    bool
+}
+
+// CopyRouteState is returned for COPY FROM STDIN to sharded relation,
+// rows are routed one by one
+type CopyRouteState struct {
+	RoutingState
+
+	Routes []*ShardRoute
+	Rows   *CopyRowRouter
+}
+
+var CopyFormatError = xerrors.New("only text and csv COPY formats are supported")
+var CopyColumnsError = xerrors.New("COPY to sharded relation requires column list with sharding key")
+
+// isCopyStmt checks if query is COPY statement
+func isCopyStmt(q string) bool {
+	q = strings.TrimLeftFunc(q, unicode.IsSpace)
+	if len(q) < 4 || !strings.EqualFold(q[:4], "copy") {
+		return false
+	}
+
+	return len(q) == 4 || !isCopyWordByte(q[4])
+}
+
+type copyToken struct {
+	val string
+	// string literal or quoted identifier
+	quoted bool
+	// offset of token in statement text
+	pos int
+}
+
+func isCopyWordByte(c byte) bool {
+	return !strings.ContainsRune(" \t\r\n\f(),;.'\"", rune(c))
+}
+
+// copyTokens splits COPY statement into words, string literals and punctuation.
+// Unquoted words are lowercased
+func copyTokens(q string) ([]copyToken, error) {
+	var ret []copyToken
+
+	for i := 0; i < len(q); {
+		c := q[i]
+
+		switch {
+		case strings.IndexByte(" \t\r\n\f", c) >= 0:
+			i++
+		case c == '-' && i+1 < len(q) && q[i+1] == '-':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("(),;.", c) >= 0:
+			ret = append(ret, copyToken{val: string(c), pos: i})
+			i++
+		case c == '\'' || c == '"':
+			val, n, err := copyQuoted(q[i:], c, false)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, copyToken{val: val, quoted: true, pos: i})
+			i += n
+		case (c == 'e' || c == 'E') && i+1 < len(q) && q[i+1] == '\'':
+			val, n, err := copyQuoted(q[i+1:], '\'', true)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, copyToken{val: val, quoted: true, pos: i})
+			i += n + 1
+		default:
+			j := i
+			for j < len(q) && isCopyWordByte(q[j]) {
+				j++
+			}
+			ret = append(ret, copyToken{val: strings.ToLower(q[i:j]), pos: i})
+			i = j
+		}
+	}
+
+	return ret, nil
+}
+
+// copyQuoted reads string literal or quoted identifier, doubled quote is unescaped.
+// Backslash escapes are processed for E'...' strings
+func copyQuoted(q string, quote byte, escapes bool) (string, int, error) {
+	var sb strings.Builder
+
+	for i := 1; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == quote && i+1 < len(q) && q[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		case c == quote:
+			return sb.String(), i + 1, nil
+		case c == '\\' && escapes && i+1 < len(q):
+			i++
+			sb.WriteByte(unescapeCopyByte(q[i]))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", 0, xerrors.New("unterminated quoted string")
+}
+
+// copyParser parses COPY statement tokens
+type copyParser struct {
+	tokens []copyToken
+	pos    int
+
+	// NULL option is set explicitly
+	null bool
+}
+
+func (p *copyParser) peek() (copyToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return copyToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *copyParser) next() (copyToken, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+// keyword consumes next token, if it is given unquoted word or punctuation
+func (p *copyParser) keyword(kw string) bool {
+	if tok, ok := p.peek(); ok && !tok.quoted && tok.val == kw {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *copyParser) ident() (string, error) {
+	tok, ok := p.next()
+	if !ok || (!tok.quoted && strings.ContainsAny(tok.val, "(),;.")) {
+		return "", xerrors.New("identifier expected")
+	}
+	return tok.val, nil
+}
+
+func (p *copyParser) str() (string, error) {
+	p.keyword("as")
+
+	tok, ok := p.next()
+	if !ok || !tok.quoted {
+		return "", xerrors.New("string literal expected")
+	}
+	return tok.val, nil
+}
+
+// skipValue skips option value, which is either single token or parenthesized list
+func (p *copyParser) skipValue() {
+	if !p.keyword("(") {
+		if tok, ok := p.peek(); ok && (tok.quoted || tok.val != "," && tok.val != ")") {
+			p.pos++
+		}
+		return
+	}
+
+	for depth := 1; depth > 0; {
+		tok, ok := p.next()
+		if !ok {
+			return
+		}
+		if !tok.quoted && tok.val == "(" {
+			depth++
+		}
+		if !tok.quoted && tok.val == ")" {
+			depth--
+		}
+	}
+}
+
+// query returns text of parenthesized query, opening parenthesis is already consumed
+func (p *copyParser) query(q string) (string, error) {
+	start, ok := p.peek()
+	if !ok {
+		return "", xerrors.New("COPY query expected")
+	}
+
+	for depth := 1; ; {
+		tok, ok := p.next()
+		if !ok {
+			return "", xerrors.New("unterminated COPY query")
+		}
+		if tok.quoted {
+			continue
+		}
+
+		switch tok.val {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(q[start.pos:tok.pos]), nil
+			}
+		}
+	}
+}
+
+// option applies COPY option. Options, that do not affect data layout, are skipped
+func (p *copyParser) option(stmt *CopyStmt, name string, legacy bool) error {
+	var err error
+	var val string
+
+	switch name {
+	case "format":
+		var tok copyToken
+		tok, _ = p.next()
+		stmt.Format = tok.val
+	case "binary", "csv":
+		stmt.Format = name
+	case "header":
+		stmt.Header = true
+		if legacy {
+			return nil
+		}
+		if tok, ok := p.peek(); ok && !tok.quoted && (tok.val == "," || tok.val == ")") {
+			return nil
+		}
+		tok, _ := p.next()
+		switch strings.ToLower(tok.val) {
+		case "false", "off", "0":
+			stmt.Header = false
+		}
+	case "delimiter", "quote", "escape":
+		if val, err = p.str(); err != nil {
+			return err
+		}
+		if len(val) != 1 {
+			return xerrors.Errorf("COPY %s must be a single one-byte character", name)
+		}
+
+		switch name {
+		case "delimiter":
+			stmt.Delimiter = val[0]
+		case "quote":
+			stmt.Quote = val[0]
+		default:
+			stmt.Escape = val[0]
+		}
+	case "null":
+		if stmt.Null, err = p.str(); err != nil {
+			return err
+		}
+		p.null = true
+	case "force":
+		// FORCE QUOTE and FORCE NOT NULL column lists
+		for tok, ok := p.peek(); ok && !tok.quoted && tok.val != ";"; tok, ok = p.peek() {
+			if tok.val == "where" {
+				break
+			}
+			p.pos++
+		}
+	default:
+		if !legacy {
+			p.skipValue()
+		}
+	}
+
+	return nil
+}
+
+// parseCopy parses COPY [ONLY] table [(columns)] | (query) FROM | TO target [[WITH] options]
+func parseCopy(q string) (*CopyStmt, error) {
+	tokens, err := copyTokens(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &copyParser{tokens: tokens}
+	if !p.keyword("copy") {
+		return nil, xerrors.New("COPY statement expected")
+	}
+	p.keyword("only")
+
+	stmt := &CopyStmt{}
+
+	if p.keyword("(") {
+		if stmt.Query, err = p.query(q); err != nil {
+			return nil, err
+		}
+	} else {
+		// schema qualified relation is sharded by its name
+		for {
+			if stmt.TableName, err = p.ident(); err != nil {
+				return nil, err
+			}
+			if !p.keyword(".") {
+				break
+			}
+		}
+	}
+
+	if stmt.Query == "" && p.keyword("(") {
+		for {
+			col, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Columns = append(stmt.Columns, col)
+
+			if p.keyword(")") {
+				break
+			}
+			if !p.keyword(",") {
+				return nil, xerrors.New("malformed COPY column list")
+			}
+		}
+	}
+
+	switch {
+	case p.keyword("from"):
+		stmt.From = true
+		stmt.Stdio = p.keyword("stdin")
+	case p.keyword("to"):
+		stmt.Stdio = p.keyword("stdout")
+	default:
+		return nil, xerrors.New("COPY direction expected")
+	}
+	if !stmt.Stdio {
+		p.next()
+	}
+
+	p.keyword("with")
+
+	if p.keyword("(") {
+		for !p.keyword(")") {
+			name, ok := p.next()
+			if !ok {
+				return nil, xerrors.New("malformed COPY options")
+			}
+			if err := p.option(stmt, name.val, false); err != nil {
+				return nil, err
+			}
+			p.keyword(",")
+		}
+	} else {
+		for tok, ok := p.next(); ok && !tok.quoted && tok.val != ";" && tok.val != "where"; tok, ok = p.next() {
+			if err := p.option(stmt, tok.val, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	stmt.Format = strings.ToLower(stmt.Format)
+	if stmt.Format == "" {
+		stmt.Format = CopyFormatText
+	}
+
+	// defaults of PostgreSQL
+	switch stmt.Format {
+	case CopyFormatText:
+		if stmt.Delimiter == 0 {
+			stmt.Delimiter = '\t'
+		}
+		if !p.null {
+			stmt.Null = `\N`
+		}
+	case CopyFormatCSV:
+		if stmt.Delimiter == 0 {
+			stmt.Delimiter = ','
+		}
+		if stmt.Quote == 0 {
+			stmt.Quote = '"'
+		}
+		if stmt.Escape == 0 {
+			stmt.Escape = stmt.Quote
+		}
+	}
+
+	return stmt, nil
+}
+
+// routeCopyTo routes COPY TO STDOUT to every datashard, which may own
+// rows of relation. Data of datashards is concatenated.
+func (qr *ProxyRouter) routeCopyTo(stmt *CopyStmt) (RoutingState, error) {
+	rctx := newRoutingContext(nil)
+
+	if stmt.Query != "" {
+		parsedStmt, err := sqlparser.Parse(stmt.Query)
+		if err != nil {
+			return nil, ParseError
+		}
+
+		routes, err := qr.matchShards(parsedStmt, rctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if routes != nil {
+			// query is not rewritten for datashards,
+			// as every one of them returns its own rows only
+			for _, route := range routes {
+				route.Query = ""
+			}
+
+			return ShardMatchState{
+				Routes: routes,
+			}, nil
+		}
+	} else {
+		rctx.addTable(stmt.TableName, "")
+	}
+
+	if !qr.isShardedQuery(rctx) {
+		// relations without sharding rules live on world shard
+		if len(rctx.tables) > 0 {
+			return WolrdRouteState{}, nil
+		}
+		return SkipRoutingState{}, nil
+	}
+
+	krs, err := qr.ListKeyRanges(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	routes := routesOf(krs, false)
+	if len(routes) == 0 {
+		return SkipRoutingState{}, nil
+	}
+
+	return ShardMatchState{
+		Routes: routes,
+	}, nil
+}
+
+// routeCopy routes COPY FROM STDIN to datashards of relation key ranges.
+// COPY of relations without sharding rules is executed on world shard
+func (qr *ProxyRouter) routeCopy(stmt *CopyStmt) (RoutingState, error) {
+	if !stmt.Stdio || stmt.From && stmt.Query != "" {
+		return nil, ParseError
+	}
+
+	if !stmt.From {
+		return qr.routeCopyTo(stmt)
+	}
+
+	if stmt.Format != CopyFormatText && stmt.Format != CopyFormatCSV {
+		return nil, CopyFormatError
+	}
+
+	rctx := newRoutingContext(nil)
+	rctx.addTable(stmt.TableName, "")
+
+	rules, err := qr.ListShardingRules(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var rule *shrule.ShardingRule
+	var keyCols []int
+
+	for _, r := range rules {
+		if !ruleApplies(r, rctx) {
+			continue
+		}
+
+		keyCols = keyCols[:0]
+		for _, col := range r.Columns() {
+			for i, c := range stmt.Columns {
+				if c == col {
+					keyCols = append(keyCols, i)
+					break
+				}
+			}
+		}
+
+		if len(keyCols) == len(r.Columns()) {
+			rule = r
+			break
+		}
+	}
+
+	if rule == nil {
+		if qr.isShardedQuery(rctx) {
+			return nil, CopyColumnsError
+		}
+		return WolrdRouteState{}, nil
+	}
+
+	krs, err := qr.ListKeyRanges(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	routes := routesOf(krs, true)
+	if len(routes) == 0 {
+		return SkipRoutingState{}, nil
+	}
+	for _, route := range routes {
+		route.Rule = rule
+	}
+
+	return CopyRouteState{
+		Routes: routes,
+		Rows: &CopyRowRouter{
+			stmt:    stmt,
+			rule:    rule,
+			keyCols: keyCols,
+			krs:     krs,
+			routes:  routes,
+			header:  stmt.Header,
+		},
+	}, nil
+}
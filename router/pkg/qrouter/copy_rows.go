@@ -0,0 +1,268 @@
+package qrouter
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"golang.org/x/xerrors"
+)
+
+// CopyRowRouter splits COPY FROM STDIN data stream into rows
+// and routes every row by its sharding key
+type CopyRowRouter struct {
+	stmt *CopyStmt
+	rule *shrule.ShardingRule
+	// positions of rule columns in COPY column list
+	keyCols []int
+	krs     []*kr.KeyRange
+	routes  []*ShardRoute
+
+	// incomplete row of previous data chunk
+	buf []byte
+	// header row is not received yet
+	header bool
+	// end of data marker is received, rest of data is ignored
+	eof bool
+}
+
+// Route consumes chunk of COPY data and returns complete rows, grouped by datashards.
+// Header row is sent to every datashard of routes
+func (r *CopyRowRouter) Route(data []byte) (map[kr.ShardKey][]byte, error) {
+	r.buf = append(r.buf, data...)
+
+	ret := map[kr.ShardKey][]byte{}
+
+	for !r.eof {
+		n, ok := r.rowEnd(r.buf)
+		if !ok {
+			break
+		}
+
+		if err := r.routeRow(r.buf[:n], ret); err != nil {
+			return nil, err
+		}
+		r.buf = r.buf[n:]
+	}
+
+	// do not hold large buffer of already routed rows
+	r.buf = append([]byte(nil), r.buf...)
+
+	return ret, nil
+}
+
+// Flush routes last row of data stream, which is not terminated by newline
+func (r *CopyRowRouter) Flush() (map[kr.ShardKey][]byte, error) {
+	ret := map[kr.ShardKey][]byte{}
+
+	if len(r.buf) > 0 && !r.eof {
+		if err := r.routeRow(r.buf, ret); err != nil {
+			return nil, err
+		}
+	}
+	r.buf = nil
+
+	return ret, nil
+}
+
+func (r *CopyRowRouter) routeRow(row []byte, ret map[kr.ShardKey][]byte) error {
+	line := bytes.TrimSuffix(bytes.TrimSuffix(row, []byte("\n")), []byte("\r"))
+
+	if bytes.Equal(line, []byte(`\.`)) {
+		r.eof = true
+		return nil
+	}
+
+	if r.header {
+		r.header = false
+		for _, route := range r.routes {
+			ret[route.Shkey] = append(ret[route.Shkey], row...)
+		}
+		return nil
+	}
+
+	var fields [][]byte
+	var nulls []bool
+	if r.stmt.Format == CopyFormatCSV {
+		fields, nulls = r.csvFields(line)
+	} else {
+		fields, nulls = r.textFields(line)
+	}
+
+	vals := keyValues{}
+	for i, col := range r.rule.Columns() {
+		pos := r.keyCols[i]
+		if pos >= len(fields) {
+			return xerrors.Errorf("missing data for sharding column %s", col)
+		}
+		if nulls[pos] {
+			return xerrors.Errorf("null value of sharding column %s", col)
+		}
+		vals[columnRef{table: r.stmt.TableName, name: col}] = fields[pos]
+	}
+
+	krs, _ := keyRangesOf(r.rule, alternative{vals: vals}, r.krs)
+	if len(krs) == 0 {
+		return xerrors.Errorf("failed to match key range for COPY row %q", line)
+	}
+
+	// rows are keyed as header, so both are sent to datashard in order
+	shkey := kr.ShardKey{Name: krs[0].ShardID, RW: true}
+	ret[shkey] = append(ret[shkey], row...)
+
+	return nil
+}
+
+// rowEnd finds length of first complete row of data, including newline.
+// Newlines are data inside CSV quoted values and after backslash in text format
+func (r *CopyRowRouter) rowEnd(data []byte) (int, bool) {
+	inQuote := false
+	quote, escape := r.stmt.Quote, r.stmt.Escape
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if r.stmt.Format != CopyFormatCSV {
+			switch c {
+			case '\\':
+				i++
+			case '\n':
+				return i + 1, true
+			}
+			continue
+		}
+
+		switch {
+		case inQuote && c == escape && escape != quote && i+1 < len(data) && (data[i+1] == quote || data[i+1] == escape):
+			i++
+		case c == quote:
+			inQuote = !inQuote
+		case !inQuote && c == '\n':
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// textFields splits text format row into de-escaped field values
+func (r *CopyRowRouter) textFields(line []byte) ([][]byte, []bool) {
+	var fields [][]byte
+	var nulls []bool
+
+	start := 0
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && line[i] == '\\' {
+			i++
+			continue
+		}
+		if i < len(line) && line[i] != r.stmt.Delimiter {
+			continue
+		}
+
+		raw := line[start:i]
+		fields = append(fields, unescapeCopyText(raw))
+		nulls = append(nulls, string(raw) == r.stmt.Null)
+		start = i + 1
+	}
+
+	return fields, nulls
+}
+
+// csvFields splits CSV row into field values. Quoted values are never null
+func (r *CopyRowRouter) csvFields(line []byte) ([][]byte, []bool) {
+	var fields [][]byte
+	var nulls []bool
+
+	quote, escape := r.stmt.Quote, r.stmt.Escape
+
+	var field []byte
+	quoted, inQuote := false, false
+
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || !inQuote && line[i] == r.stmt.Delimiter {
+			fields = append(fields, field)
+			nulls = append(nulls, !quoted && string(field) == r.stmt.Null)
+			field, quoted = nil, false
+			continue
+		}
+
+		c := line[i]
+		switch {
+		case inQuote && c == escape && i+1 < len(line) && (line[i+1] == quote || line[i+1] == escape) && (escape != quote || line[i+1] == quote):
+			i++
+			field = append(field, line[i])
+		case c == quote:
+			inQuote = !inQuote
+			quoted = true
+		default:
+			field = append(field, c)
+		}
+	}
+
+	return fields, nulls
+}
+
+func unescapeCopyByte(c byte) byte {
+	switch c {
+	case 'b':
+		return '\b'
+	case 'f':
+		return '\f'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'v':
+		return '\v'
+	default:
+		return c
+	}
+}
+
+// unescapeCopyText decodes backslash sequences of text format value
+func unescapeCopyText(raw []byte) []byte {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return raw
+	}
+
+	ret := make([]byte, 0, len(raw))
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			ret = append(ret, raw[i])
+			continue
+		}
+		i++
+
+		switch c := raw[i]; {
+		case c >= '0' && c <= '7':
+			j := i
+			for j < len(raw) && j < i+3 && raw[j] >= '0' && raw[j] <= '7' {
+				j++
+			}
+			v, _ := strconv.ParseUint(string(raw[i:j]), 8, 16)
+			ret = append(ret, byte(v))
+			i = j - 1
+		case c == 'x' && i+1 < len(raw) && isHexDigit(raw[i+1]):
+			j := i + 1
+			for j < len(raw) && j < i+3 && isHexDigit(raw[j]) {
+				j++
+			}
+			v, _ := strconv.ParseUint(string(raw[i+1:j]), 16, 8)
+			ret = append(ret, byte(v))
+			i = j - 1
+		default:
+			ret = append(ret, unescapeCopyByte(c))
+		}
+	}
+
+	return ret
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
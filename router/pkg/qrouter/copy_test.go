@@ -0,0 +1,221 @@
+package qrouter
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/xerrors"
+)
+
+func TestParseCopy(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  CopyStmt
+		err   bool
+	}{
+		{
+			query: "COPY t (id, name) FROM STDIN",
+			want: CopyStmt{
+				TableName: "t", Columns: []string{"id", "name"}, From: true, Stdio: true,
+				Format: CopyFormatText, Delimiter: '\t', Null: `\N`,
+			},
+		},
+		{
+			query: "copy only public.t (\"Id\") from stdin with (format csv, header, delimiter ';')",
+			want: CopyStmt{
+				TableName: "t", Columns: []string{"Id"}, From: true, Stdio: true,
+				Format: CopyFormatCSV, Delimiter: ';', Quote: '"', Escape: '"', Header: true,
+			},
+		},
+		{
+			query: "COPY t FROM STDIN WITH CSV HEADER QUOTE '''' NULL 'none'",
+			want: CopyStmt{
+				TableName: "t", From: true, Stdio: true,
+				Format: CopyFormatCSV, Delimiter: ',', Quote: '\'', Escape: '\'', Null: "none", Header: true,
+			},
+		},
+		{
+			query: "COPY t (id) FROM STDIN (FORMAT text, NULL '', HEADER false)",
+			want: CopyStmt{
+				TableName: "t", Columns: []string{"id"}, From: true, Stdio: true,
+				Format: CopyFormatText, Delimiter: '\t',
+			},
+		},
+		{
+			query: "COPY (SELECT * FROM t WHERE id = 1) TO STDOUT",
+			want: CopyStmt{
+				Query: "SELECT * FROM t WHERE id = 1", Stdio: true,
+				Format: CopyFormatText, Delimiter: '\t', Null: `\N`,
+			},
+		},
+		{
+			query: "COPY t TO '/tmp/t.csv' CSV",
+			want: CopyStmt{
+				TableName: "t",
+				Format:    CopyFormatCSV, Delimiter: ',', Quote: '"', Escape: '"',
+			},
+		},
+		{
+			query: "COPY t FROM STDIN (DELIMITER ';;')",
+			err:   true,
+		},
+		{
+			query: "COPY t (id, FROM STDIN",
+			err:   true,
+		},
+		{
+			query: "COPY t",
+			err:   true,
+		},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := parseCopy(tt.query)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("parseCopy(%q) = %+v, want error", tt.query, stmt)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCopy(%q) error = %v", tt.query, err)
+			}
+
+			if !reflect.DeepEqual(*stmt, tt.want) {
+				t.Errorf("parseCopy(%q) = %+v, want %+v", tt.query, *stmt, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeCopyText(t *testing.T) {
+	for _, tt := range []struct {
+		raw  string
+		want string
+	}{
+		{raw: `abc`, want: "abc"},
+		{raw: `a\tb\nc`, want: "a\tb\nc"},
+		{raw: `\\`, want: `\`},
+		{raw: `\061\x32`, want: "12"},
+		{raw: `\q`, want: "q"},
+		{raw: `a\`, want: `a\`},
+	} {
+		if got := unescapeCopyText([]byte(tt.raw)); string(got) != tt.want {
+			t.Errorf("unescapeCopyText(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestCopyRowRouter(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		query  string
+		chunks []string
+		want   map[string]string
+		err    bool
+	}{
+		{
+			name:   "text rows",
+			query:  "COPY t (id, name) FROM STDIN",
+			chunks: []string{"1\ta\n15\tb\n2\tc\n"},
+			want:   map[string]string{"sh1": "1\ta\n2\tc\n", "sh2": "15\tb\n"},
+		},
+		{
+			name:   "rows split across chunks",
+			query:  "COPY t (name, id) FROM STDIN",
+			chunks: []string{"a\t", "1\nb\t1", "5\n", "c\t3"},
+			want:   map[string]string{"sh1": "a\t1\nc\t3", "sh2": "b\t15\n"},
+		},
+		{
+			name:   "escaped newline and end of data",
+			query:  "COPY t (name, id) FROM STDIN",
+			chunks: []string{"a\\\nb\t12\n\\.\n1\tjunk\n"},
+			want:   map[string]string{"sh2": "a\\\nb\t12\n"},
+		},
+		{
+			name:   "csv with quoted newline",
+			query:  "COPY t (name, id) FROM STDIN (FORMAT csv)",
+			chunks: []string{"\"x\n,y\",5\n", "z,\"19\"\r\n"},
+			want:   map[string]string{"sh1": "\"x\n,y\",5\n", "sh2": "z,\"19\"\r\n"},
+		},
+		{
+			name:   "csv with header",
+			query:  "COPY t (name, id) FROM STDIN (FORMAT csv, HEADER)",
+			chunks: []string{"name,id\na,5\nb,6\n", "c,19\n"},
+			want:   map[string]string{"sh1": "name,id\na,5\nb,6\n", "sh2": "name,id\nc,19\n"},
+		},
+		{
+			name:   "null sharding key",
+			query:  "COPY t (id, name) FROM STDIN",
+			chunks: []string{"\\N\ta\n"},
+			err:    true,
+		},
+		{
+			name:   "missing sharding key",
+			query:  "COPY t (name, id) FROM STDIN",
+			chunks: []string{"a\n"},
+			err:    true,
+		},
+		{
+			name:   "row outside key ranges",
+			query:  "COPY t (id) FROM STDIN",
+			chunks: []string{"999\n"},
+			err:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := newTestRouter(t)
+
+			state, err := qr.Route(tt.query)
+			if err != nil {
+				t.Fatalf("Route(%q) error = %v", tt.query, err)
+			}
+			v, ok := state.(CopyRouteState)
+			if !ok {
+				t.Fatalf("Route(%q) state = %T, want CopyRouteState", tt.query, state)
+			}
+
+			got := map[string]string{}
+			for _, chunk := range tt.chunks {
+				rows, err := v.Rows.Route([]byte(chunk))
+				if err != nil {
+					if tt.err {
+						return
+					}
+					t.Fatalf("Route(%q) error = %v", chunk, err)
+				}
+				for shkey, data := range rows {
+					got[shkey.Name] += string(data)
+				}
+			}
+
+			rows, err := v.Rows.Flush()
+			if err != nil {
+				if tt.err {
+					return
+				}
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if tt.err {
+				t.Fatalf("rows are routed, want error")
+			}
+			for shkey, data := range rows {
+				got[shkey.Name] += string(data)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("routed rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteCopy(t *testing.T) {
+	qr := newTestRouter(t)
+
+	if _, err := qr.Route("COPY t (name) FROM STDIN"); !xerrors.Is(err, CopyColumnsError) {
+		t.Errorf("COPY without sharding key error = %v, want %v", err, CopyColumnsError)
+	}
+	if _, err := qr.Route("COPY t (id) FROM STDIN (FORMAT binary)"); !xerrors.Is(err, CopyFormatError) {
+		t.Errorf("binary COPY error = %v, want %v", err, CopyFormatError)
+	}
+}
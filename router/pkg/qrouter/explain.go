@@ -0,0 +1,54 @@
+package qrouter
+
+import (
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+)
+
+// statement types of explained query
+const (
+	StmtSelect   = "SELECT"
+	StmtInsert   = "INSERT"
+	StmtUpdate   = "UPDATE"
+	StmtDelete   = "DELETE"
+	StmtCopy     = "COPY"
+	StmtDDL      = "DDL"
+	StmtSet      = "SET"
+	StmtShow     = "SHOW"
+	StmtOther    = "OTHER"
+	StmtUnparsed = "UNPARSED"
+)
+
+// StatementType returns type of query statement, as it is parsed by router
+func StatementType(q string) string {
+	if _, stripped, err := parseHintComment(q); err == nil {
+		q = stripped
+	}
+
+	if isCopyStmt(q) {
+		return StmtCopy
+	}
+
+	parsedStmt, err := sqlparser.Parse(rewritePlaceholders(q))
+	if err != nil {
+		return StmtUnparsed
+	}
+
+	switch parsedStmt.(type) {
+	case *sqlparser.Select, *sqlparser.Union, *sqlparser.ParenSelect:
+		return StmtSelect
+	case *sqlparser.Insert:
+		return StmtInsert
+	case *sqlparser.Update:
+		return StmtUpdate
+	case *sqlparser.Delete:
+		return StmtDelete
+	case *sqlparser.DDL, *sqlparser.CreateTable, *sqlparser.TruncateTable:
+		return StmtDDL
+	case *sqlparser.Set:
+		return StmtSet
+	case *sqlparser.Show:
+		return StmtShow
+	default:
+		return StmtOther
+	}
+}
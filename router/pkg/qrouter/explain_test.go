@@ -0,0 +1,21 @@
+package qrouter
+
+import "testing"
+
+func TestStatementType(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT 1", want: StmtSelect},
+		{query: "/* spqr: shard=sh1 */ INSERT INTO t (id) VALUES (1)", want: StmtInsert},
+		{query: "UPDATE t SET v = $1 WHERE id = $2", want: StmtUpdate},
+		{query: "DELETE FROM t WHERE id = 1", want: StmtDelete},
+		{query: "COPY t FROM STDIN", want: StmtCopy},
+		{query: "VACUUM t", want: StmtUnparsed},
+	} {
+		if got := StatementType(tt.query); got != tt.want {
+			t.Errorf("StatementType(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package qrouter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"github.com/wal-g/tracelog"
+	"golang.org/x/xerrors"
+)
+
+// routing hints
+const (
+	// datashard, where query is executed
+	HintShard = "shard"
+	// sharding key of query, which has no sharding key predicate. Key is hashed
+	// and encoded by sharding rule of query relations. Components of composite key
+	// are separated by comma, as sharding_key='1,abc'
+	HintShardingKey = "sharding_key"
+	// read-write routes query to primary, read-only routes query to any host
+	HintTargetSessionAttrs = "target_session_attrs"
+)
+
+const (
+	TargetSessionAttrsRW = "read-write"
+	TargetSessionAttrsRO = "read-only"
+)
+
+// hintPrefix marks comment with routing hints
+const hintPrefix = "spqr:"
+
+// hintSettingPrefix marks session settings, which are routing hints
+const hintSettingPrefix = "spqr."
+
+// Hints are routing hints, given in leading comment of query, as /* spqr: shard=sh2 */,
+// or by session setting, as SET spqr.sharding_key = 12345. Sharding key of session
+// routes only queries, which are not routed by their own predicates
+type Hints struct {
+	Shard              string
+	ShardingKey        string
+	TargetSessionAttrs string
+}
+
+// Set sets value of hint. Hint is reset by empty value
+func (h *Hints) Set(name, value string) error {
+	switch strings.ToLower(name) {
+	case HintShard:
+		h.Shard = value
+	case HintShardingKey:
+		h.ShardingKey = value
+	case HintTargetSessionAttrs:
+		switch value = strings.ToLower(value); value {
+		case TargetSessionAttrsRW, TargetSessionAttrsRO, "":
+			h.TargetSessionAttrs = value
+		default:
+			return xerrors.Errorf("invalid value %v of routing hint %v", value, name)
+		}
+	default:
+		return xerrors.Errorf("unknown routing hint %v", name)
+	}
+
+	return nil
+}
+
+// override returns hints, where hints of query take precedence over session hints
+func (h Hints) override(query Hints) Hints {
+	if query.Shard != "" || query.ShardingKey != "" {
+		h.Shard, h.ShardingKey = query.Shard, query.ShardingKey
+	}
+	if query.TargetSessionAttrs != "" {
+		h.TargetSessionAttrs = query.TargetSessionAttrs
+	}
+
+	return h
+}
+
+// parseHintComment extracts routing hints from leading comment of query
+// and returns query without hint comment
+func parseHintComment(q string) (Hints, string, error) {
+	var hints Hints
+
+	trimmed := strings.TrimSpace(q)
+	if !strings.HasPrefix(trimmed, "/*") {
+		return hints, q, nil
+	}
+
+	end := strings.Index(trimmed, "*/")
+	if end < 0 {
+		return hints, q, nil
+	}
+
+	comment := strings.TrimSpace(trimmed[2:end])
+	if !strings.HasPrefix(strings.ToLower(comment), hintPrefix) {
+		return hints, q, nil
+	}
+
+	for _, pair := range splitHints(comment[len(hintPrefix):]) {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return hints, q, xerrors.Errorf("failed to parse routing hint %v", pair)
+		}
+
+		if err := hints.Set(kv[0], strings.Trim(kv[1], `'"`)); err != nil {
+			return hints, q, err
+		}
+	}
+
+	return hints, trimmed[end+2:], nil
+}
+
+// splitHints splits hint comment into name=value pairs, separated
+// by commas or spaces. Separators in quoted values are kept
+func splitHints(comment string) []string {
+	var pairs []string
+	var sb strings.Builder
+	var quote rune
+
+	for _, r := range comment {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ',' || unicode.IsSpace(r):
+			if sb.Len() > 0 {
+				pairs = append(pairs, sb.String())
+				sb.Reset()
+			}
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	if sb.Len() > 0 {
+		pairs = append(pairs, sb.String())
+	}
+
+	return pairs
+}
+
+var hintSettingRe = regexp.MustCompile(`(?is)^\s*set\s+(?:session\s+)?spqr\.(\w+)\s*(?:=|\s+to\s+)\s*(.*?)\s*;?\s*$`)
+var hintResetRe = regexp.MustCompile(`(?is)^\s*reset\s+spqr\.(\w+)\s*;?\s*$`)
+
+// ParseHintSetting checks if query sets or resets routing hint of session,
+// as SET spqr.sharding_key = 12345 or RESET spqr.sharding_key, and returns
+// name and value of hint. Value of reset hint is empty
+func ParseHintSetting(q string) (string, string, bool) {
+	if m := hintResetRe.FindStringSubmatch(q); m != nil {
+		return m[1], "", true
+	}
+
+	m := hintSettingRe.FindStringSubmatch(q)
+	if m == nil {
+		return "", "", false
+	}
+
+	value := m[2]
+	if strings.EqualFold(value, "default") {
+		value = ""
+	}
+
+	return m[1], strings.Trim(value, `'"`), true
+}
+
+// hintRule returns sharding rule, which resolves sharding key of routing hint: the only rule
+// of query relations or, if query is not parsed, the only rule at all. Key is not resolved,
+// if several rules apply, as they may hash and encode key differently
+func (qr *ProxyRouter) hintRule(rctx *routingContext, parsed bool) (*shrule.ShardingRule, error) {
+	rules, err := qr.ListShardingRules(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var ret *shrule.ShardingRule
+
+	for _, rule := range rules {
+		if parsed && !ruleApplies(rule, rctx) {
+			continue
+		}
+
+		if ret != nil {
+			tracelog.InfoLogger.Printf("sharding rules %v and %v apply to sharding key of routing hint", ret.ID(), rule.ID())
+			return nil, HintRuleError
+		}
+		ret = rule
+	}
+
+	if ret == nil {
+		tracelog.InfoLogger.Printf("no sharding rule applies to sharding key of routing hint")
+		return nil, HintRuleError
+	}
+
+	return ret, nil
+}
+
+// routeByHint routes query to datashard of hint
+func (qr *ProxyRouter) routeByHint(q string, hints Hints) (*ShardRoute, error) {
+	if hints.Shard != "" {
+		if _, ok := qr.DataShardCfgs[hints.Shard]; !ok {
+			tracelog.InfoLogger.Printf("datashard %v of routing hint is not found", hints.Shard)
+			return nil, HintShardError
+		}
+
+		return &ShardRoute{
+			Shkey: kr.ShardKey{
+				Name: hints.Shard,
+			},
+		}, nil
+	}
+
+	rctx := newRoutingContext(nil)
+
+	stmt, err := sqlparser.Parse(rewritePlaceholders(q))
+	if err == nil {
+		rctx.addStatementTables(stmt)
+	}
+
+	rule, err := qr.hintRule(rctx, err == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	components := strings.Split(hints.ShardingKey, ",")
+	if len(components) != len(rule.Columns()) {
+		tracelog.InfoLogger.Printf("sharding key %v of routing hint does not match columns %v of sharding rule %v", hints.ShardingKey, rule.Columns(), rule.ID())
+		return nil, HintRuleError
+	}
+
+	vals := keyValues{}
+	for i, col := range rule.Columns() {
+		vals[columnRef{table: rule.TableName(), name: col}] = []byte(strings.TrimSpace(components[i]))
+	}
+
+	krs, err := qr.ListKeyRanges(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	matched, ok := keyRangesOf(rule, alternative{vals: vals}, krs)
+	if !ok {
+		return nil, HintRuleError
+	}
+	if len(matched) == 0 {
+		return nil, MatchShardError
+	}
+
+	return &ShardRoute{
+		Shkey: kr.ShardKey{
+			Name: matched[0].ShardID,
+		},
+		Matchedkr: matched[0],
+		Rule:      rule,
+	}, nil
+}
+
+// applyTargetSessionAttrs routes query to primary or any host of datashards
+func applyTargetSessionAttrs(state RoutingState, hints Hints) RoutingState {
+	v, ok := state.(ShardMatchState)
+	if !ok || hints.TargetSessionAttrs == "" {
+		return state
+	}
+
+	for _, route := range v.Routes {
+		route.Shkey.RW = hints.TargetSessionAttrs == TargetSessionAttrsRW
+	}
+
+	return v
+}
+
+// routeWithHint routes query to datashard of shard or sharding key hint
+func (qr *ProxyRouter) routeWithHint(q string, hints Hints) (RoutingState, error) {
+	route, err := qr.routeByHint(q, hints)
+	if err != nil {
+		return nil, err
+	}
+	route.Shkey.RW = !ReadOnly(q)
+
+	return ShardMatchState{
+		Routes: []*ShardRoute{route},
+	}, nil
+}
+
+func (qr *ProxyRouter) RouteWithHints(q string, bind *BindParams, session Hints) (RoutingState, error) {
+	query, q, err := parseHintComment(q)
+	if err != nil {
+		tracelog.InfoLogger.PrintError(err)
+		return nil, HintError
+	}
+
+	hints := session.override(query)
+
+	var state RoutingState
+
+	switch {
+	case query.Shard != "" || query.ShardingKey != "" || hints.Shard != "":
+		state, err = qr.routeWithHint(q, hints)
+	case hints.ShardingKey != "":
+		state, err = qr.routeWithParams(q, bind)
+		if _, ok := state.(SkipRoutingState); ok || err == ParseError || err == NoShardingKeyError {
+			state, err = qr.routeWithHint(q, hints)
+		}
+	default:
+		state, err = qr.routeWithParams(q, bind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return applyTargetSessionAttrs(state, hints), nil
+}
@@ -0,0 +1,270 @@
+package qrouter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/hashfunction"
+	"github.com/pg-sharding/spqr/pkg/models/datashards"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+)
+
+func TestParseHintComment(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		query string
+		hints Hints
+		rest  string
+		err   bool
+	}{
+		{
+			name:  "no comment",
+			query: "SELECT 1",
+			rest:  "SELECT 1",
+		},
+		{
+			name:  "comment without hints",
+			query: "/* report */ SELECT 1",
+			rest:  "/* report */ SELECT 1",
+		},
+		{
+			name:  "shard",
+			query: "/* spqr: shard=sh2 */ SELECT 1",
+			hints: Hints{Shard: "sh2"},
+			rest:  " SELECT 1",
+		},
+		{
+			name:  "several hints",
+			query: "  /* spqr: sharding_key=12345, target_session_attrs=read-only */SELECT 1",
+			hints: Hints{ShardingKey: "12345", TargetSessionAttrs: TargetSessionAttrsRO},
+			rest:  "SELECT 1",
+		},
+		{
+			name:  "quoted composite key",
+			query: "/* spqr: sharding_key='1, abc' */ SELECT 1",
+			hints: Hints{ShardingKey: "1, abc"},
+			rest:  " SELECT 1",
+		},
+		{
+			name:  "unknown hint",
+			query: "/* spqr: replica=1 */ SELECT 1",
+			err:   true,
+		},
+		{
+			name:  "invalid target session attrs",
+			query: "/* spqr: target_session_attrs=any */ SELECT 1",
+			err:   true,
+		},
+		{
+			name:  "hint without value",
+			query: "/* spqr: shard */ SELECT 1",
+			err:   true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hints, rest, err := parseHintComment(tt.query)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("parseHintComment(%q) succeeded, want error", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHintComment(%q) error = %v", tt.query, err)
+			}
+
+			if hints != tt.hints {
+				t.Errorf("parseHintComment(%q) hints = %+v, want %+v", tt.query, hints, tt.hints)
+			}
+			if rest != tt.rest {
+				t.Errorf("parseHintComment(%q) query = %q, want %q", tt.query, rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestSplitHints(t *testing.T) {
+	for _, tt := range []struct {
+		comment string
+		pairs   []string
+	}{
+		{comment: "", pairs: nil},
+		{comment: " shard=sh1 ", pairs: []string{"shard=sh1"}},
+		{comment: "a=1,b=2 c=3", pairs: []string{"a=1", "b=2", "c=3"}},
+		{comment: `a='1, 2' b="x y"`, pairs: []string{"a='1, 2'", `b="x y"`}},
+	} {
+		if pairs := splitHints(tt.comment); !reflect.DeepEqual(pairs, tt.pairs) {
+			t.Errorf("splitHints(%q) = %q, want %q", tt.comment, pairs, tt.pairs)
+		}
+	}
+}
+
+func TestParseHintSetting(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		name  string
+		value string
+		ok    bool
+	}{
+		{query: "SET spqr.sharding_key = 12345", name: "sharding_key", value: "12345", ok: true},
+		{query: "set session spqr.shard to 'sh1';", name: "shard", value: "sh1", ok: true},
+		{query: "SET spqr.shard = DEFAULT", name: "shard", value: "", ok: true},
+		{query: "RESET spqr.target_session_attrs", name: "target_session_attrs", value: "", ok: true},
+		{query: "SET search_path = public", ok: false},
+		{query: "SELECT 'SET spqr.shard = sh1'", ok: false},
+	} {
+		name, value, ok := ParseHintSetting(tt.query)
+		if name != tt.name || value != tt.value || ok != tt.ok {
+			t.Errorf("ParseHintSetting(%q) = %q, %q, %v, want %q, %q, %v", tt.query, name, value, ok, tt.name, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestRouteWithHints(t *testing.T) {
+	// murmur3 hashes of 1 and 2 are 2484513939 and 19522071
+	qr := newTestRouterWith(t, shrule.NewShardingRule("r1", "t", []string{"id"}, hashfunction.HashFunctionMurmur3),
+		&kr.KeyRange{ID: "k1", ShardID: "sh1", LowerBound: []byte("0"), UpperBound: []byte("2147483648"), KeyType: kr.KeyTypeInteger},
+		&kr.KeyRange{ID: "k2", ShardID: "sh2", LowerBound: []byte("2147483648"), UpperBound: []byte("4294967296"), KeyType: kr.KeyTypeInteger},
+	)
+	if err := qr.AddShardingRule(context.TODO(), shrule.NewShardingRule("r2", "u", []string{"id"}, "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := qr.AddDataShard(context.TODO(), datashards.NewDataShard("sh1", &config.ShardCfg{})); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		query   string
+		session Hints
+		shard   string
+		rw      bool
+		err     error
+	}{
+		{
+			name:  "hashed sharding key",
+			query: "/* spqr: sharding_key=1 */ SELECT * FROM t",
+			shard: "sh2",
+		},
+		{
+			name:  "another hashed sharding key",
+			query: "/* spqr: sharding_key=2 */ UPDATE t SET v = 1",
+			shard: "sh1",
+			rw:    true,
+		},
+		{
+			name:    "sharding key of session",
+			query:   "SELECT * FROM t",
+			session: Hints{ShardingKey: "1"},
+			shard:   "sh2",
+		},
+		{
+			name:    "predicate of query overrides sharding key of session",
+			query:   "SELECT * FROM t WHERE id = 2",
+			session: Hints{ShardingKey: "1"},
+			shard:   "sh1",
+		},
+		{
+			name:    "sharding key of session routes delete without sharding key",
+			query:   "DELETE FROM t WHERE v = 1",
+			session: Hints{ShardingKey: "1"},
+			shard:   "sh2",
+			rw:      true,
+		},
+		{
+			name:    "hint of query overrides session",
+			query:   "/* spqr: sharding_key=2 */ SELECT * FROM t",
+			session: Hints{ShardingKey: "1"},
+			shard:   "sh1",
+		},
+		{
+			name:  "primary of read-only query",
+			query: "/* spqr: sharding_key=1 target_session_attrs=read-write */ SELECT * FROM t",
+			shard: "sh2",
+			rw:    true,
+		},
+		{
+			name:  "shard",
+			query: "/* spqr: shard=sh1 */ SELECT * FROM t",
+			shard: "sh1",
+		},
+		{
+			name:  "unknown shard",
+			query: "/* spqr: shard=sh3 */ SELECT * FROM t",
+			err:   HintShardError,
+		},
+		{
+			name:  "relations of several sharding rules",
+			query: "/* spqr: sharding_key=1 */ SELECT * FROM t JOIN u ON t.id = u.id",
+			err:   HintRuleError,
+		},
+		{
+			name:  "relation without sharding rule",
+			query: "/* spqr: sharding_key=1 */ SELECT * FROM w",
+			err:   HintRuleError,
+		},
+		{
+			name:  "composite key of single column rule",
+			query: "/* spqr: sharding_key='1,2' */ SELECT * FROM t",
+			err:   HintRuleError,
+		},
+		{
+			name:  "malformed hint",
+			query: "/* spqr: sharding_key */ SELECT * FROM t",
+			err:   HintError,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := qr.RouteWithHints(tt.query, nil, tt.session)
+			if tt.err != nil {
+				if !xerrors.Is(err, tt.err) {
+					t.Fatalf("RouteWithHints(%q) error = %v, want %v", tt.query, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RouteWithHints(%q) error = %v", tt.query, err)
+			}
+
+			v, ok := state.(ShardMatchState)
+			if !ok || len(v.Routes) != 1 {
+				t.Fatalf("RouteWithHints(%q) state = %+v, want single route", tt.query, state)
+			}
+			if route := v.Routes[0]; route.Shkey.Name != tt.shard || route.Shkey.RW != tt.rw {
+				t.Errorf("RouteWithHints(%q) route = %+v, want %v, rw %v", tt.query, route.Shkey, tt.shard, tt.rw)
+			}
+		})
+	}
+}
+
+func TestRouteWithSessionShardingKey(t *testing.T) {
+	// murmur3 hashes of 1 and 2 are 2484513939 and 19522071
+	qr := newTestRouterWith(t, shrule.NewShardingRule("r1", "t", []string{"id"}, hashfunction.HashFunctionMurmur3),
+		&kr.KeyRange{ID: "k1", ShardID: "sh1", LowerBound: []byte("0"), UpperBound: []byte("2147483648"), KeyType: kr.KeyTypeInteger},
+		&kr.KeyRange{ID: "k2", ShardID: "sh2", LowerBound: []byte("2147483648"), UpperBound: []byte("4294967296"), KeyType: kr.KeyTypeInteger},
+	)
+
+	query := "INSERT INTO t (id, v) VALUES (1, 'a'), (2, 'b')"
+	state, err := qr.RouteWithHints(query, nil, Hints{ShardingKey: "1"})
+	if err != nil {
+		t.Fatalf("RouteWithHints(%q) error = %v", query, err)
+	}
+
+	// rows are split by their own sharding keys
+	shards, queries := routedShards(t, state)
+	if want := []string{"sh1", "sh2"}; !reflect.DeepEqual(shards, want) {
+		t.Errorf("RouteWithHints(%q) shards = %v, want %v", query, shards, want)
+	}
+	want := map[string]string{
+		"sh1": "insert into t(id, v) values (2, 'b')",
+		"sh2": "insert into t(id, v) values (1, 'a')",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("RouteWithHints(%q) queries = %v, want %v", query, queries, want)
+	}
+}
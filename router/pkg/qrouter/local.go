@@ -1,30 +1,194 @@
 package qrouter
 
 import (
+	"context"
+
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/models/datashards"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/sequences"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"github.com/pg-sharding/spqr/pkg/models/transactions"
+	"github.com/pg-sharding/spqr/qdb"
+	"github.com/pg-sharding/spqr/qdb/mem"
+	"golang.org/x/xerrors"
 )
 
+// LocalQrouter routes every query to its only datashard.
+// Local router has no key ranges and sharding rules
 type LocalQrouter struct {
-	QueryRouter
 	shid string
+	cfg  *config.ShardCfg
+
+	// sequences are allocated in qdb of router, unless coordinator is configured
+	qdb  qdb.QrouterDB
+	seqs *sequenceCache
 }
 
 var _ QueryRouter = &LocalQrouter{}
 
 func NewLocalQrouter(shid string) (*LocalQrouter, error) {
-	return &LocalQrouter{
+	if shid == "" {
+		return nil, xerrors.New("local datashard is not set for local router")
+	}
+
+	db, err := mem.NewQrouterDBMem()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &LocalQrouter{
 		shid: shid,
-	}, nil
+		cfg:  config.RouterConfig().RouterConfig.ShardMapping[shid],
+		qdb:  db,
+	}
+
+	mgr, err := newSequenceMgr(l)
+	if err != nil {
+		return nil, err
+	}
+	l.seqs = newSequenceCache(mgr, config.RouterConfig().QRouterCfg.SequenceBlockSize)
+
+	return l, nil
+}
+
+func (l *LocalQrouter) DataShardsRoutes() []*ShardRoute {
+	return []*ShardRoute{
+		{
+			Shkey: kr.ShardKey{
+				Name: l.shid,
+				RW:   true,
+			},
+		},
+	}
+}
+
+func (l *LocalQrouter) WorldShardsRoutes() []*ShardRoute {
+	return nil
+}
+
+func (l *LocalQrouter) Shards() []string {
+	return []string{l.shid}
+}
+
+func (l *LocalQrouter) WorldShards() []string {
+	return nil
+}
+
+func (l *LocalQrouter) AddDataShard(_ context.Context, ds *datashards.DataShard) error {
+	if ds.ID != l.shid {
+		return xerrors.Errorf("failed to add datashard %v, local router serves only %v: %w", ds.ID, l.shid, LocalTopologyError)
+	}
+
+	if ds.Cfg != nil {
+		l.cfg = ds.Cfg
+	}
+
+	return nil
+}
+
+func (l *LocalQrouter) ListDataShards(_ context.Context) []*datashards.DataShard {
+	return []*datashards.DataShard{
+		datashards.NewDataShard(l.shid, l.cfg),
+	}
+}
+
+func (l *LocalQrouter) AddWorldShard(name string, _ *config.ShardCfg) error {
+	return xerrors.Errorf("failed to add world datashard %v: %w", name, LocalTopologyError)
+}
+
+// AddLocalTable is no-op, as every table is local
+func (l *LocalQrouter) AddLocalTable(_ string) error {
+	return nil
+}
+
+func (l *LocalQrouter) ListKeyRanges(_ context.Context) ([]*kr.KeyRange, error) {
+	return nil, nil
+}
+
+func (l *LocalQrouter) AddKeyRange(_ context.Context, keyRange *kr.KeyRange) error {
+	return xerrors.Errorf("failed to add key range %v: %w", keyRange.ID, LocalTopologyError)
+}
+
+func (l *LocalQrouter) Lock(_ context.Context, krid string) (*kr.KeyRange, error) {
+	return nil, xerrors.Errorf("failed to lock key range %v: %w", krid, LocalTopologyError)
+}
+
+func (l *LocalQrouter) Unlock(_ context.Context, krid string) error {
+	return xerrors.Errorf("failed to unlock key range %v: %w", krid, LocalTopologyError)
+}
+
+func (l *LocalQrouter) Split(_ context.Context, req *kr.SplitKeyRange) error {
+	return xerrors.Errorf("failed to split key range %v: %w", req.SourceID, LocalTopologyError)
+}
+
+func (l *LocalQrouter) Unite(_ context.Context, req *kr.UniteKeyRange) error {
+	return xerrors.Errorf("failed to unite key ranges %v and %v: %w", req.KeyRangeIDLeft, req.KeyRangeIDRight, LocalTopologyError)
+}
+
+func (l *LocalQrouter) AddShardingRule(_ context.Context, rule *shrule.ShardingRule) error {
+	return xerrors.Errorf("failed to add sharding rule %v: %w", rule.ID(), LocalTopologyError)
+}
+
+func (l *LocalQrouter) ListShardingRules(_ context.Context) ([]*shrule.ShardingRule, error) {
+	return nil, nil
+}
+
+// RecordTransaction fails, as transaction of local router never spans several datashards
+func (l *LocalQrouter) RecordTransaction(_ context.Context, tx *transactions.Transaction) (*transactions.Transaction, error) {
+	return nil, xerrors.Errorf("failed to record distributed transaction %v: %w", tx.GID, LocalTopologyError)
 }
 
+func (l *LocalQrouter) DeleteTransaction(_ context.Context, gid string) error {
+	return xerrors.Errorf("failed to delete distributed transaction %v: %w", gid, LocalTopologyError)
+}
+
+func (l *LocalQrouter) NextRange(ctx context.Context, name string, count int64) (*sequences.Range, error) {
+	first, err := l.qdb.AllocateSequenceRange(ctx, name, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return sequences.NewRange(name, first, count), nil
+}
+
+func (l *LocalQrouter) NextVal(ctx context.Context, name string) (int64, error) {
+	return l.seqs.nextVal(ctx, name)
+}
+
+func (l *LocalQrouter) Subscribe(_ string, _ *qdb.KeyRangeStatus, _ chan<- interface{}) error {
+	return nil
+}
+
+// Route routes query to the only datashard. Read-only query may be executed on replica
 func (l *LocalQrouter) Route(q string) (RoutingState, error) {
 	return ShardMatchState{
 		Routes: []*ShardRoute{
 			{
 				Shkey: kr.ShardKey{
 					Name: l.shid,
+					RW:   !ReadOnly(q),
 				},
 			},
 		},
 	}, nil
 }
+
+func (l *LocalQrouter) RouteWithParams(q string, _ *BindParams) (RoutingState, error) {
+	return l.Route(q)
+}
+
+// RouteWithHints routes query to the only datashard, so only target session attributes are honoured
+func (l *LocalQrouter) RouteWithHints(q string, _ *BindParams, session Hints) (RoutingState, error) {
+	query, _, err := parseHintComment(q)
+	if err != nil {
+		return nil, HintError
+	}
+
+	state, err := l.Route(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyTargetSessionAttrs(state, session.override(query)), nil
+}
@@ -0,0 +1,208 @@
+package qrouter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/router/pkg/server"
+)
+
+// hasAggregates checks if select list contains aggregate functions
+func hasAggregates(exprs sqlparser.SelectExprs) bool {
+	found := false
+
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if fexpr, ok := node.(*sqlparser.FuncExpr); ok && fexpr.IsAggregate() {
+			found = true
+		}
+		return !found, nil
+	}, exprs)
+
+	return found
+}
+
+// uintValue extracts non-negative integer constant or parameter value
+func (qr *ProxyRouter) uintValue(expr sqlparser.Expr, rctx *routingContext) (uint64, bool) {
+	val, ok := qr.exprValue(expr, rctx)
+	if !ok {
+		return 0, false
+	}
+
+	ret, err := strconv.ParseUint(string(val), 10, 64)
+	return ret, err == nil
+}
+
+// sortKeyOf resolves ORDER BY expression to column of result. Expressions,
+// missing in select list, are added to it as hidden columns
+func sortKeyOf(stmt *sqlparser.Select, order *sqlparser.Order, plan *server.MergePlan) (server.SortKey, bool) {
+	key := server.SortKey{
+		Desc: order.Direction == sqlparser.DescScr,
+	}
+
+	star := false
+
+	switch expr := order.Expr.(type) {
+	case *sqlparser.SQLVal:
+		if expr.Type != sqlparser.IntVal {
+			return key, false
+		}
+
+		pos, err := strconv.Atoi(string(expr.Val))
+		if err != nil || pos < 1 || pos > len(stmt.SelectExprs) {
+			return key, false
+		}
+
+		// position of column is unknown after star expansion
+		for _, sexpr := range stmt.SelectExprs[:pos] {
+			if _, ok := sexpr.(*sqlparser.StarExpr); ok {
+				return key, false
+			}
+		}
+
+		key.Column = pos - 1
+		return key, true
+
+	case *sqlparser.ColName:
+		for i, sexpr := range stmt.SelectExprs {
+			switch v := sexpr.(type) {
+			case *sqlparser.StarExpr:
+				star = true
+			case *sqlparser.AliasedExpr:
+				name := ""
+				if !v.As.IsEmpty() {
+					name = v.As.String()
+				} else if col, ok := v.Expr.(*sqlparser.ColName); ok && (expr.Qualifier.IsEmpty() || col.Qualifier == expr.Qualifier) {
+					name = col.Name.String()
+				}
+
+				if name == "" || !expr.Name.EqualString(name) {
+					continue
+				}
+
+				if star {
+					key.Name = name
+				} else {
+					key.Column = i
+				}
+				return key, true
+			}
+		}
+
+		if star {
+			// column is expected to be expanded from star
+			key.Name = expr.Name.String()
+			return key, true
+		}
+	}
+
+	key.Name = fmt.Sprintf("spqr_sort_%d", plan.HiddenColumns)
+	stmt.SelectExprs = append(stmt.SelectExprs, &sqlparser.AliasedExpr{
+		Expr: order.Expr,
+		As:   sqlparser.NewColIdent(key.Name),
+	})
+	plan.HiddenColumns++
+
+	return key, true
+}
+
+// planMerge plans merge of scattered SELECT rows, sorted by ORDER BY or limited by LIMIT.
+// Every datashard is queried with ORDER BY and LIMIT count + offset, then sorted streams
+// of datashards are merged and OFFSET and LIMIT are applied to merged result.
+// Aggregates are computed partially on every datashard and combined by router
+func (qr *ProxyRouter) planMerge(stmt *sqlparser.Select, routes []*ShardRoute, rctx *routingContext) (*server.MergePlan, error) {
+	if len(routes) < 2 {
+		return nil, nil
+	}
+
+	grouped := len(stmt.GroupBy) > 0 || hasAggregates(stmt.SelectExprs)
+	if !grouped && len(stmt.OrderBy) == 0 && stmt.Limit == nil {
+		return nil, nil
+	}
+
+	if stmt.Distinct != "" || stmt.Having != nil {
+		if grouped {
+			return nil, AggregateError
+		}
+		return nil, nil
+	}
+
+	plan := &server.MergePlan{
+		Grouped: grouped,
+	}
+
+	if stmt.Limit != nil {
+		if stmt.Limit.Offset != nil {
+			offset, ok := qr.uintValue(stmt.Limit.Offset, rctx)
+			if !ok {
+				return nil, nil
+			}
+			plan.Offset = offset
+		}
+
+		if stmt.Limit.Rowcount != nil {
+			limit, ok := qr.uintValue(stmt.Limit.Rowcount, rctx)
+			if !ok {
+				return nil, nil
+			}
+			plan.Limit = &limit
+		}
+	}
+
+	selectExprs, orderBy, limit := stmt.SelectExprs, stmt.OrderBy, stmt.Limit
+	restore := func() {
+		stmt.SelectExprs, stmt.OrderBy, stmt.Limit = selectExprs, orderBy, limit
+	}
+
+	for _, order := range stmt.OrderBy {
+		key, ok := sortKeyOf(stmt, order, plan)
+		if !ok {
+			restore()
+			if grouped {
+				return nil, AggregateError
+			}
+			return nil, nil
+		}
+		plan.SortKeys = append(plan.SortKeys, key)
+	}
+
+	rewrite := plan.Offset > 0 || plan.HiddenColumns > 0
+
+	if grouped {
+		if !planAggregates(stmt, plan) {
+			restore()
+			return nil, AggregateError
+		}
+
+		// groups are sorted and limited after aggregation
+		rewrite = rewrite || plan.HiddenColumns > 0 || stmt.Limit != nil
+		stmt.OrderBy, stmt.Limit = nil, nil
+	} else if plan.Limit != nil {
+		stmt.Limit = &sqlparser.Limit{
+			Rowcount: sqlparser.NewIntVal([]byte(strconv.FormatUint(*plan.Limit+plan.Offset, 10))),
+		}
+	} else {
+		stmt.Limit = nil
+	}
+
+	if !rewrite {
+		// datashards execute query as is
+		restore()
+		return plan, nil
+	}
+
+	q, ok := deparse(stmt)
+	if !ok || rctx.extended {
+		restore()
+		if grouped {
+			return nil, AggregateError
+		}
+		return nil, nil
+	}
+
+	for _, route := range routes {
+		route.Query = q
+	}
+
+	return plan, nil
+}
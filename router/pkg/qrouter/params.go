@@ -0,0 +1,205 @@
+package qrouter
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+const (
+	textFormat   = 0
+	binaryFormat = 1
+)
+
+// types of parameters, which are decoded in binary format
+const (
+	int8OID    = 20
+	int2OID    = 21
+	int4OID    = 23
+	textOID    = 25
+	bpcharOID  = 1042
+	varcharOID = 1043
+)
+
+// BindParams are parameters of query, executed via extended protocol
+type BindParams struct {
+	Values  [][]byte
+	Formats []int16
+	// types of parameters, specified in Parse message. Zero type is not specified
+	Types []uint32
+}
+
+// paramValue resolves bind variable (:vN, which is $N in PostgreSQL syntax)
+// to its text representation
+func (rctx *routingContext) paramValue(name string) ([]byte, bool) {
+	if rctx == nil || !strings.HasPrefix(name, ":v") {
+		return nil, false
+	}
+
+	n, err := strconv.Atoi(name[2:])
+	if err != nil || n < 1 || n > len(rctx.params) {
+		return nil, false
+	}
+
+	val := rctx.params[n-1]
+	if val == nil {
+		// NULL never matches any key range
+		return nil, false
+	}
+
+	var format int16 = textFormat
+	switch len(rctx.formats) {
+	case 0:
+	case 1:
+		format = rctx.formats[0]
+	default:
+		if n <= len(rctx.formats) {
+			format = rctx.formats[n-1]
+		}
+	}
+
+	if format != binaryFormat {
+		return val, true
+	}
+
+	var typ uint32
+	if n <= len(rctx.types) {
+		typ = rctx.types[n-1]
+	}
+
+	// binary value is decoded by parameter type, so
+	// parameters of unspecified type are not routed by
+	switch {
+	case typ == int2OID && len(val) == 2:
+		return []byte(strconv.FormatInt(int64(int16(binary.BigEndian.Uint16(val))), 10)), true
+	case typ == int4OID && len(val) == 4:
+		return []byte(strconv.FormatInt(int64(int32(binary.BigEndian.Uint32(val))), 10)), true
+	case typ == int8OID && len(val) == 8:
+		return []byte(strconv.FormatInt(int64(binary.BigEndian.Uint64(val)), 10)), true
+	case typ == textOID || typ == varcharOID || typ == bpcharOID:
+		// binary representation of text is text itself
+		return val, true
+	default:
+		return nil, false
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9' || c == '$'
+}
+
+// quotedEnd returns end of quoted literal or identifier, which starts at i.
+// Backslash escapes quote in escape string constants
+func quotedEnd(q string, i int, escapes bool) int {
+	quote := q[i]
+
+	for j := i + 1; j < len(q); j++ {
+		switch {
+		case escapes && q[j] == '\\':
+			j++
+		case q[j] == quote:
+			if j+1 < len(q) && q[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+
+	return len(q)
+}
+
+// commentEnd returns end of comment, which starts at i. Block comments may be nested
+func commentEnd(q string, i int) int {
+	if q[i] == '-' {
+		if j := strings.IndexByte(q[i:], '\n'); j >= 0 {
+			return i + j + 1
+		}
+		return len(q)
+	}
+
+	depth := 0
+	for j := i; j+1 < len(q); j++ {
+		switch {
+		case q[j] == '/' && q[j+1] == '*':
+			depth++
+			j++
+		case q[j] == '*' && q[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+
+	return len(q)
+}
+
+// dollarQuotedEnd returns end of dollar-quoted string, which starts at i,
+// or -1, if there is no dollar quote at i
+func dollarQuotedEnd(q string, i int) int {
+	j := i + 1
+	if j < len(q) && isIdentStart(q[j]) {
+		for j < len(q) && isIdentChar(q[j]) && q[j] != '$' {
+			j++
+		}
+	}
+	if j >= len(q) || q[j] != '$' {
+		return -1
+	}
+
+	tag := q[i : j+1]
+	if k := strings.Index(q[j+1:], tag); k >= 0 {
+		return j + 1 + k + len(tag)
+	}
+
+	return len(q)
+}
+
+// rewritePlaceholders replaces PostgreSQL $N placeholders with bind variables
+// sql parser understands. Comments, quoted literals and identifiers are left as is.
+func rewritePlaceholders(q string) string {
+	if !strings.Contains(q, "$") {
+		return q
+	}
+
+	var sb strings.Builder
+
+	for i := 0; i < len(q); {
+		c := q[i]
+		end := i + 1
+
+		switch {
+		case strings.HasPrefix(q[i:], "--"), strings.HasPrefix(q[i:], "/*"):
+			end = commentEnd(q, i)
+		case c == '\'' || c == '"':
+			end = quotedEnd(q, i, false)
+		case isIdentStart(c):
+			// identifiers may contain $, e.g. col$1
+			for end < len(q) && isIdentChar(q[end]) {
+				end++
+			}
+			if end-i == 1 && (c == 'e' || c == 'E') && end < len(q) && q[end] == '\'' {
+				end = quotedEnd(q, end, true)
+			}
+		case c == '$' && i+1 < len(q) && q[i+1] >= '0' && q[i+1] <= '9':
+			sb.WriteString(":v")
+			i++
+			continue
+		case c == '$':
+			if j := dollarQuotedEnd(q, i); j > 0 {
+				end = j
+			}
+		}
+
+		sb.WriteString(q[i:end])
+		i = end
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package qrouter
+
+import "testing"
+
+func TestRewritePlaceholders(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT 1", want: "SELECT 1"},
+		{query: "SELECT * FROM t WHERE id = $1", want: "SELECT * FROM t WHERE id = :v1"},
+		{query: "INSERT INTO t VALUES ($1, $12)", want: "INSERT INTO t VALUES (:v1, :v12)"},
+		{query: "SELECT col$1 FROM t WHERE id=$2", want: "SELECT col$1 FROM t WHERE id=:v2"},
+		{query: "SELECT '$1', \"$2\" FROM t WHERE id = $3", want: "SELECT '$1', \"$2\" FROM t WHERE id = :v3"},
+		{query: "SELECT 'it''s $1', $2", want: "SELECT 'it''s $1', :v2"},
+		{query: `SELECT E'\'$1', $2`, want: `SELECT E'\'$1', :v2`},
+		{query: "SELECT $$ $1 $$, $tag$ $2 $tag$, $3", want: "SELECT $$ $1 $$, $tag$ $2 $tag$, :v3"},
+		{query: "SELECT $1 -- $2\n, $3", want: "SELECT :v1 -- $2\n, :v3"},
+		{query: "SELECT /* $1 /* $2 */ $3 */ $4", want: "SELECT /* $1 /* $2 */ $3 */ :v4"},
+	} {
+		if got := rewritePlaceholders(tt.query); got != tt.want {
+			t.Errorf("rewritePlaceholders(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParamValue(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		bind  *BindParams
+		param string
+		want  string
+		ok    bool
+	}{
+		{
+			name:  "text",
+			bind:  &BindParams{Values: [][]byte{[]byte("12")}},
+			param: ":v1",
+			want:  "12",
+			ok:    true,
+		},
+		{
+			name:  "second parameter",
+			bind:  &BindParams{Values: [][]byte{[]byte("1"), []byte("2")}},
+			param: ":v2",
+			want:  "2",
+			ok:    true,
+		},
+		{
+			name:  "out of range",
+			bind:  &BindParams{Values: [][]byte{[]byte("1")}},
+			param: ":v2",
+		},
+		{
+			name:  "not a bind variable",
+			bind:  &BindParams{Values: [][]byte{[]byte("1")}},
+			param: "v1",
+		},
+		{
+			name:  "null",
+			bind:  &BindParams{Values: [][]byte{nil}},
+			param: ":v1",
+		},
+		{
+			name: "binary int2",
+			bind: &BindParams{
+				Values:  [][]byte{{0xff, 0xfe}},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{int2OID},
+			},
+			param: ":v1",
+			want:  "-2",
+			ok:    true,
+		},
+		{
+			name: "binary int4",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 1, 0, 0}},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{int4OID},
+			},
+			param: ":v1",
+			want:  "65536",
+			ok:    true,
+		},
+		{
+			name: "binary int8",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 0, 0, 1, 0, 0, 0, 0}},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{int8OID},
+			},
+			param: ":v1",
+			want:  "4294967296",
+			ok:    true,
+		},
+		{
+			name: "binary text",
+			bind: &BindParams{
+				Values:  [][]byte{[]byte("abc")},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{varcharOID},
+			},
+			param: ":v1",
+			want:  "abc",
+			ok:    true,
+		},
+		{
+			name: "binary of unspecified type",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 0, 0, 1}},
+				Formats: []int16{binaryFormat},
+			},
+			param: ":v1",
+		},
+		{
+			name: "binary of mismatched length",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 1}},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{int4OID},
+			},
+			param: ":v1",
+		},
+		{
+			name: "format per parameter",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 0, 0, 7}, []byte("8")},
+				Formats: []int16{binaryFormat, textFormat},
+				Types:   []uint32{int4OID, int4OID},
+			},
+			param: ":v2",
+			want:  "8",
+			ok:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			val, ok := newRoutingContext(tt.bind).paramValue(tt.param)
+			if ok != tt.ok || string(val) != tt.want {
+				t.Errorf("paramValue(%q) = %q, %v, want %q, %v", tt.param, val, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package qrouter
+
+import (
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/wal-g/tracelog"
+)
+
+// keyValues is a conjunction of column = value predicates
+type keyValues map[columnRef][]byte
+
+// value looks up value of table column. Empty table name
+// matches column with given name in any relation.
+func (kv keyValues) value(table string, name string) ([]byte, bool) {
+	if table != "" {
+		val, ok := kv[columnRef{table: table, name: name}]
+		return val, ok
+	}
+
+	for ref, val := range kv {
+		if ref.name == name {
+			return val, true
+		}
+	}
+
+	return nil, false
+}
+
+// keyBound is a bound of column values interval
+type keyBound struct {
+	val       []byte
+	inclusive bool
+}
+
+// keyInterval restricts column values from below and above,
+// nil bound means that interval is unbounded from that side
+type keyInterval struct {
+	lower *keyBound
+	upper *keyBound
+}
+
+// overlaps checks if interval intersects key range [LowerBound, UpperBound).
+// Intervals are restrictions of single column key
+func (i keyInterval) overlaps(keyRange *kr.KeyRange) (bool, error) {
+	if i.lower != nil {
+		res, err := kr.CmpKeys(keyRange.KeyType, 1, i.lower.val, keyRange.UpperBound)
+		if err != nil {
+			return false, err
+		}
+		if res >= 0 {
+			return false, nil
+		}
+	}
+
+	if i.upper != nil {
+		res, err := kr.CmpKeys(keyRange.KeyType, 1, keyRange.LowerBound, i.upper.val)
+		if err != nil {
+			return false, err
+		}
+		if res > 0 || (res == 0 && !i.upper.inclusive) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// overlapsAll checks if every interval of column intersects key range.
+// Intervals are not intersected with each other, so result is an upper estimate
+func overlapsAll(keyRange *kr.KeyRange, intervals []keyInterval) bool {
+	for _, interval := range intervals {
+		ok, err := interval.overlaps(keyRange)
+		if err != nil {
+			// bound is not a valid value of key range type
+			tracelog.InfoLogger.PrintError(err)
+			return false
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// alternative is a conjunction of column = value and
+// column interval predicates
+type alternative struct {
+	vals      keyValues
+	intervals map[columnRef][]keyInterval
+}
+
+func (a alternative) empty() bool {
+	return len(a.vals) == 0 && len(a.intervals) == 0
+}
+
+// intervalsOf looks up intervals of table column, the same way as keyValues.value
+func (a alternative) intervalsOf(table string, name string) []keyInterval {
+	if table != "" {
+		return a.intervals[columnRef{table: table, name: name}]
+	}
+
+	var ret []keyInterval
+	for ref, intervals := range a.intervals {
+		if ref.name == name {
+			ret = append(ret, intervals...)
+		}
+	}
+
+	return ret
+}
+
+// predicate is a disjunction of alternatives, which restricts set
+// of rows touched by query. Empty alternative does not restrict
+// query at all.
+type predicate []alternative
+
+// maxAlternatives limits predicate size, larger predicates are
+// relaxed to their less restrictive part
+const maxAlternatives = 1024
+
+func truePredicate() predicate {
+	return predicate{alternative{}}
+}
+
+func valuePredicate(ref columnRef, val []byte) predicate {
+	return predicate{alternative{vals: keyValues{ref: val}}}
+}
+
+func intervalPredicate(ref columnRef, interval keyInterval) predicate {
+	return predicate{alternative{intervals: map[columnRef][]keyInterval{ref: {interval}}}}
+}
+
+func (p predicate) restricted() bool {
+	for _, alt := range p {
+		if alt.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p predicate) or(other predicate) predicate {
+	if !p.restricted() || !other.restricted() || len(p)+len(other) > maxAlternatives {
+		return truePredicate()
+	}
+
+	ret := make(predicate, 0, len(p)+len(other))
+	ret = append(ret, p...)
+	return append(ret, other...)
+}
+
+func (p predicate) and(other predicate) predicate {
+	if len(p)*len(other) > maxAlternatives {
+		// conjunction is not less restrictive than any of its parts
+		if len(p) < len(other) {
+			return p
+		}
+		return other
+	}
+
+	ret := make(predicate, 0, len(p)*len(other))
+	for _, lalt := range p {
+		for _, ralt := range other {
+			alt := alternative{
+				vals:      make(keyValues, len(lalt.vals)+len(ralt.vals)),
+				intervals: make(map[columnRef][]keyInterval, len(lalt.intervals)+len(ralt.intervals)),
+			}
+			for ref, val := range ralt.vals {
+				alt.vals[ref] = val
+			}
+			// contradicting values select no rows, so any of them
+			// could be used for routing
+			for ref, val := range lalt.vals {
+				alt.vals[ref] = val
+			}
+			// all intervals of column restrict its values
+			for ref, intervals := range lalt.intervals {
+				alt.intervals[ref] = append(alt.intervals[ref], intervals...)
+			}
+			for ref, intervals := range ralt.intervals {
+				alt.intervals[ref] = append(alt.intervals[ref], intervals...)
+			}
+			ret = append(ret, alt)
+		}
+	}
+
+	return ret
+}
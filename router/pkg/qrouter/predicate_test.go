@@ -0,0 +1,216 @@
+package qrouter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pg-sharding/spqr/pkg/hashfunction"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+)
+
+func bound(val string, inclusive bool) *keyBound {
+	return &keyBound{val: []byte(val), inclusive: inclusive}
+}
+
+func TestKeyIntervalOverlaps(t *testing.T) {
+	// [10, 20)
+	keyRange := &kr.KeyRange{LowerBound: []byte("10"), UpperBound: []byte("20"), KeyType: kr.KeyTypeInteger}
+
+	for _, tt := range []struct {
+		name     string
+		interval keyInterval
+		want     bool
+	}{
+		{name: "unbounded", interval: keyInterval{}, want: true},
+		{name: "above lower bound", interval: keyInterval{lower: bound("15", true)}, want: true},
+		{name: "above upper bound", interval: keyInterval{lower: bound("20", true)}, want: false},
+		{name: "below upper bound", interval: keyInterval{upper: bound("10", true)}, want: true},
+		{name: "below lower bound", interval: keyInterval{upper: bound("10", false)}, want: false},
+		{name: "inside", interval: keyInterval{lower: bound("12", false), upper: bound("13", false)}, want: true},
+		{name: "around", interval: keyInterval{lower: bound("5", true), upper: bound("25", true)}, want: true},
+		{name: "numeric order", interval: keyInterval{lower: bound("9", false), upper: bound("100", false)}, want: true},
+		{name: "below", interval: keyInterval{lower: bound("1", true), upper: bound("9", true)}, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.interval.overlaps(keyRange)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("overlaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (keyInterval{lower: bound("abc", true)}).overlaps(keyRange); err == nil {
+		t.Errorf("overlaps() of non-integer bound succeeded, want error")
+	}
+}
+
+func TestKeyValuesValue(t *testing.T) {
+	vals := keyValues{
+		columnRef{table: "t", name: "id"}:  []byte("1"),
+		columnRef{table: "u", name: "uid"}: []byte("2"),
+	}
+
+	for _, tt := range []struct {
+		table string
+		name  string
+		want  string
+		ok    bool
+	}{
+		{table: "t", name: "id", want: "1", ok: true},
+		{table: "u", name: "id", ok: false},
+		{table: "", name: "uid", want: "2", ok: true},
+		{table: "", name: "name", ok: false},
+	} {
+		val, ok := vals.value(tt.table, tt.name)
+		if ok != tt.ok || string(val) != tt.want {
+			t.Errorf("value(%q, %q) = %q, %v, want %q, %v", tt.table, tt.name, val, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPredicate(t *testing.T) {
+	id := columnRef{table: "t", name: "id"}
+	name := columnRef{table: "t", name: "name"}
+
+	for _, tt := range []struct {
+		name         string
+		pred         predicate
+		alternatives int
+		restricted   bool
+	}{
+		{
+			name:         "true",
+			pred:         truePredicate(),
+			alternatives: 1,
+			restricted:   false,
+		},
+		{
+			name:         "or of values",
+			pred:         valuePredicate(id, []byte("1")).or(valuePredicate(id, []byte("2"))),
+			alternatives: 2,
+			restricted:   true,
+		},
+		{
+			name:         "or with unrestricted alternative",
+			pred:         valuePredicate(id, []byte("1")).or(truePredicate()),
+			alternatives: 1,
+			restricted:   false,
+		},
+		{
+			name:         "and restricts unrestricted predicate",
+			pred:         truePredicate().and(valuePredicate(id, []byte("1"))),
+			alternatives: 1,
+			restricted:   true,
+		},
+		{
+			name: "and distributes over or",
+			pred: valuePredicate(id, []byte("1")).or(valuePredicate(id, []byte("2"))).
+				and(valuePredicate(name, []byte("a")).or(valuePredicate(name, []byte("b")))),
+			alternatives: 4,
+			restricted:   true,
+		},
+		{
+			name:         "and of interval",
+			pred:         valuePredicate(name, []byte("a")).and(intervalPredicate(id, keyInterval{lower: bound("1", true)})),
+			alternatives: 1,
+			restricted:   true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.pred) != tt.alternatives {
+				t.Errorf("predicate has %d alternatives, want %d", len(tt.pred), tt.alternatives)
+			}
+			if tt.pred.restricted() != tt.restricted {
+				t.Errorf("restricted() = %v, want %v", tt.pred.restricted(), tt.restricted)
+			}
+		})
+	}
+}
+
+func TestPredicateIsBounded(t *testing.T) {
+	id := columnRef{table: "t", name: "id"}
+
+	pred := predicate{}
+	for i := 0; i < maxAlternatives; i++ {
+		pred = pred.or(valuePredicate(id, []byte("1")))
+	}
+	if len(pred) != maxAlternatives {
+		t.Fatalf("predicate has %d alternatives, want %d", len(pred), maxAlternatives)
+	}
+
+	if relaxed := pred.or(valuePredicate(id, []byte("2"))); relaxed.restricted() {
+		t.Errorf("or() of too many alternatives is restricted")
+	}
+	// conjunction is relaxed to its smaller part
+	if relaxed := pred.and(valuePredicate(id, []byte("1")).or(valuePredicate(id, []byte("2")))); len(relaxed) != 2 {
+		t.Errorf("and() of too many alternatives has %d alternatives, want 2", len(relaxed))
+	}
+}
+
+func TestKeyRangesOf(t *testing.T) {
+	krs := []*kr.KeyRange{
+		{ID: "k1", ShardID: "sh1", LowerBound: []byte("1"), UpperBound: []byte("11"), KeyType: kr.KeyTypeInteger},
+		{ID: "k2", ShardID: "sh2", LowerBound: []byte("11"), UpperBound: []byte("21"), KeyType: kr.KeyTypeInteger},
+	}
+	id := columnRef{table: "t", name: "id"}
+
+	for _, tt := range []struct {
+		name string
+		rule *shrule.ShardingRule
+		alt  alternative
+		want []string
+		ok   bool
+	}{
+		{
+			name: "value",
+			rule: shrule.NewShardingRule("r1", "t", []string{"id"}, ""),
+			alt:  valuePredicate(id, []byte("15"))[0],
+			want: []string{"k2"},
+			ok:   true,
+		},
+		{
+			name: "value outside key ranges",
+			rule: shrule.NewShardingRule("r1", "t", []string{"id"}, ""),
+			alt:  valuePredicate(id, []byte("999"))[0],
+			ok:   true,
+		},
+		{
+			name: "interval",
+			rule: shrule.NewShardingRule("r1", "t", []string{"id"}, ""),
+			alt:  intervalPredicate(id, keyInterval{lower: bound("5", true)})[0],
+			want: []string{"k1", "k2"},
+			ok:   true,
+		},
+		{
+			name: "interval of hashed key",
+			rule: shrule.NewShardingRule("r1", "t", []string{"id"}, hashfunction.HashFunctionMurmur3),
+			alt:  intervalPredicate(id, keyInterval{lower: bound("5", true)})[0],
+			ok:   false,
+		},
+		{
+			name: "other column",
+			rule: shrule.NewShardingRule("r1", "t", []string{"uid"}, ""),
+			alt:  valuePredicate(id, []byte("15"))[0],
+			ok:   false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := keyRangesOf(tt.rule, tt.alt, krs)
+			if ok != tt.ok {
+				t.Fatalf("keyRangesOf() ok = %v, want %v", ok, tt.ok)
+			}
+
+			var ids []string
+			for _, keyRange := range matched {
+				ids = append(ids, keyRange.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("keyRangesOf() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
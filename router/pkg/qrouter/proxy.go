@@ -6,26 +6,27 @@ import (
 	"math/rand"
 
 	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/hashfunction"
 	"github.com/pg-sharding/spqr/pkg/models/datashards"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"github.com/pg-sharding/spqr/pkg/models/transactions"
 	"github.com/pg-sharding/spqr/qdb"
+	"github.com/pg-sharding/spqr/qdb/etcdqdb"
 	"github.com/pg-sharding/spqr/qdb/mem"
 	"github.com/wal-g/tracelog"
 	"golang.org/x/xerrors"
 )
 
 type ProxyRouter struct {
-	Rules []*shrule.ShardingRule
-
-	ColumnMapping map[string]struct{}
-	LocalTables   map[string]struct{}
+	LocalTables map[string]struct{}
 
 	// shards
 	DataShardCfgs  map[string]*config.ShardCfg
 	WorldShardCfgs map[string]*config.ShardCfg
 
-	qdb qdb.QrouterDB
+	qdb  qdb.QrouterDB
+	seqs *sequenceCache
 }
 
 func (qr *ProxyRouter) ListDataShards(ctx context.Context) []*datashards.DataShard {
@@ -38,7 +39,17 @@ func (qr *ProxyRouter) ListDataShards(ctx context.Context) []*datashards.DataSha
 
 
 func (qr *ProxyRouter) ListShardingRules(ctx context.Context) ([]*shrule.ShardingRule, error) {
-	return qr.Rules, nil
+	rules, err := qr.qdb.ListShardingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*shrule.ShardingRule
+	for _, rule := range rules {
+		ret = append(ret, shrule.ShardingRuleFromDB(rule))
+	}
+
+	return ret, nil
 }
 
 func (qr *ProxyRouter) AddWorldShard(name string, cfg *config.ShardCfg) error {
@@ -97,13 +108,44 @@ func NewProxyRouter() (*ProxyRouter, error) {
 		return nil, err
 	}
 
-	return &ProxyRouter{
-		ColumnMapping:  map[string]struct{}{},
+	return newProxyRouter(db)
+}
+
+// NewShardingRouter creates router, which shares topology in qdb with other routers.
+// Topology is cached by router and dropped, when it is changed in qdb
+func NewShardingRouter(ctx context.Context, qdbAddr string) (*ProxyRouter, error) {
+	if qdbAddr == "" {
+		return nil, xerrors.New("qdb address is not set for sharding router")
+	}
+
+	db, err := etcdqdb.NewEtcdQDB(qdbAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := newTopologyCache(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProxyRouter(cache)
+}
+
+func newProxyRouter(db qdb.QrouterDB) (*ProxyRouter, error) {
+	qr := &ProxyRouter{
+		LocalTables:    map[string]struct{}{},
 		DataShardCfgs:  map[string]*config.ShardCfg{},
 		WorldShardCfgs: map[string]*config.ShardCfg{},
 		qdb:            db,
-		Rules:          []*shrule.ShardingRule{},
-	}, nil
+	}
+
+	mgr, err := newSequenceMgr(qr)
+	if err != nil {
+		return nil, err
+	}
+	qr.seqs = newSequenceCache(mgr, config.RouterConfig().QRouterCfg.SequenceBlockSize)
+
+	return qr, nil
 }
 
 func (qr *ProxyRouter) Subscribe(krid string, krst *qdb.KeyRangeStatus, noitfyio chan<- interface{}) error {
@@ -159,10 +201,26 @@ func (qr *ProxyRouter) Split(ctx context.Context, req *kr.SplitKeyRange) error {
 		&qdb.KeyRange{
 			LowerBound: req.Bound,
 			UpperBound: krOld.UpperBound,
-			KeyRangeID: req.SourceID,
+			ShardID:    krOld.ShardID,
+			KeyRangeID: req.Krid,
+			KeyType:    krOld.KeyType,
 		},
 	)
 
+	columns := kr.KeyColumns(krOld.LowerBound, krOld.UpperBound, req.Bound)
+	if ok, err := kr.KeyRangeFromDB(krOld).Contains(columns, req.Bound); err != nil {
+		return err
+	} else if !ok {
+		return xerrors.Errorf("split bound %s is out of key range %v", req.Bound, req.SourceID)
+	}
+
+	// source key range would be left empty
+	if res, err := kr.CmpKeys(krNew.KeyType, columns, req.Bound, krOld.LowerBound); err != nil {
+		return err
+	} else if res == 0 {
+		return xerrors.Errorf("split bound %s equals lower bound of key range %v", req.Bound, req.SourceID)
+	}
+
 	_ = qr.qdb.AddKeyRange(ctx, krNew.ToSQL())
 	krOld.UpperBound = req.Bound
 	_ = qr.qdb.UpdateKeyRange(ctx, krOld)
@@ -215,12 +273,15 @@ func (qr *ProxyRouter) ListKeyRanges(ctx context.Context) ([]*kr.KeyRange, error
 }
 
 func (qr *ProxyRouter) AddShardingRule(ctx context.Context, rule *shrule.ShardingRule) error {
-	if len(rule.Columns()) != 0 {
-		return xerrors.New("only single column sharding rules are supported for now")
+	if len(rule.Columns()) == 0 {
+		return xerrors.New("sharding rule should contain at least one column")
 	}
 
-	qr.ColumnMapping[rule.Columns()[0]] = struct{}{}
-	return nil
+	if err := hashfunction.ValidateHashFunction(rule.HashFunction()); err != nil {
+		return err
+	}
+
+	return qr.qdb.AddShardingRule(ctx, rule.ToSQL())
 }
 
 func (qr *ProxyRouter) AddLocalTable(tname string) error {
@@ -228,18 +289,43 @@ func (qr *ProxyRouter) AddLocalTable(tname string) error {
 	return nil
 }
 
-func (qr *ProxyRouter) AddKeyRange(ctx context.Context, kr *kr.KeyRange) error {
-	return qr.qdb.AddKeyRange(ctx, kr.ToSQL())
+func (qr *ProxyRouter) AddKeyRange(ctx context.Context, keyRange *kr.KeyRange) error {
+	if err := keyRange.Validate(); err != nil {
+		return err
+	}
+
+	return qr.qdb.AddKeyRange(ctx, keyRange.ToSQL())
+}
+
+func (qr *ProxyRouter) RecordTransaction(ctx context.Context, tx *transactions.Transaction) (*transactions.Transaction, error) {
+	recorded, err := qr.qdb.RecordTransaction(ctx, tx.ToSQL())
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions.TransactionFromDB(recorded), nil
+}
+
+func (qr *ProxyRouter) DeleteTransaction(ctx context.Context, gid string) error {
+	return qr.qdb.DeleteTransaction(ctx, gid)
 }
 
-func (qr *ProxyRouter) routeByIndx(i []byte) *kr.KeyRange {
+func (qr *ProxyRouter) routeByIndx(columns int, i []byte) *kr.KeyRange {
 
 	krs, _ := qr.qdb.ListKeyRanges(context.TODO())
 
-	for _, keyRange := range krs {
-		tracelog.InfoLogger.Printf("comparing %v with key range %v %v", i, keyRange.LowerBound, keyRange.UpperBound)
-		if kr.CmpRanges(keyRange.LowerBound, i) && kr.CmpRanges(i, keyRange.UpperBound) {
-			return kr.KeyRangeFromDB(keyRange)
+	for _, krdb := range krs {
+		keyRange := kr.KeyRangeFromDB(krdb)
+
+		tracelog.InfoLogger.Printf("comparing %s with key range %s %s of type %s", i, keyRange.LowerBound, keyRange.UpperBound, keyRange.KeyType)
+		ok, err := keyRange.Contains(columns, i)
+		if err != nil {
+			// key is not a valid value of key range type
+			tracelog.InfoLogger.PrintError(err)
+			continue
+		}
+		if ok {
+			return keyRange
 		}
 	}
 
@@ -248,62 +334,327 @@ func (qr *ProxyRouter) routeByIndx(i []byte) *kr.KeyRange {
 	}
 }
 
-func (qr *ProxyRouter) matchShkey(expr sqlparser.Expr) bool {
+func (qr *ProxyRouter) exprValue(expr sqlparser.Expr, rctx *routingContext) ([]byte, bool) {
+	sqlval, ok := expr.(*sqlparser.SQLVal)
+	if !ok {
+		return nil, false
+	}
+
+	if sqlval.Type == sqlparser.ValArg {
+		return rctx.paramValue(string(sqlval.Val))
+	}
+
+	return sqlval.Val, true
+}
+
+// columnValue extracts column and its value of col = val comparison
+func (qr *ProxyRouter) columnValue(texpr *sqlparser.ComparisonExpr, rctx *routingContext) (*sqlparser.ColName, []byte, bool) {
+	colExpr, valExpr := texpr.Left, texpr.Right
+	if _, ok := colExpr.(*sqlparser.ColName); !ok {
+		colExpr, valExpr = valExpr, colExpr
+	}
+
+	col, ok := colExpr.(*sqlparser.ColName)
+	if !ok {
+		return nil, nil, false
+	}
+
+	val, ok := qr.exprValue(valExpr, rctx)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return col, val, true
+}
+
+// predicateOf gathers col = val, col IN (...), col BETWEEN a AND b
+// and col < val (<=, >, >=) predicates, combined with AND and OR
+func (qr *ProxyRouter) predicateOf(expr sqlparser.Expr, rctx *routingContext) predicate {
 	switch texpr := expr.(type) {
-	case sqlparser.ValTuple:
-		for _, val := range texpr {
-			if qr.matchShkey(val) {
-				return true
+	case *sqlparser.AndExpr:
+		return qr.predicateOf(texpr.Left, rctx).and(qr.predicateOf(texpr.Right, rctx))
+	case *sqlparser.OrExpr:
+		return qr.predicateOf(texpr.Left, rctx).or(qr.predicateOf(texpr.Right, rctx))
+	case *sqlparser.ParenExpr:
+		return qr.predicateOf(texpr.Expr, rctx)
+	case *sqlparser.ComparisonExpr:
+		switch texpr.Operator {
+		case sqlparser.EqualStr:
+			col, val, ok := qr.columnValue(texpr, rctx)
+			if !ok {
+				return truePredicate()
+			}
+
+			tracelog.InfoLogger.Printf("parsed val %s for column %s", val, sqlparser.String(col))
+			return valuePredicate(rctx.resolveColumn(col), val)
+		case sqlparser.InStr:
+			col, ok := texpr.Left.(*sqlparser.ColName)
+			if !ok {
+				return truePredicate()
+			}
+			tuple, ok := texpr.Right.(sqlparser.ValTuple)
+			if !ok || len(tuple) == 0 || len(tuple) > maxAlternatives {
+				return truePredicate()
+			}
+
+			ref := rctx.resolveColumn(col)
+			ret := make(predicate, 0, len(tuple))
+
+			for _, elem := range tuple {
+				val, ok := qr.exprValue(elem, rctx)
+				if !ok {
+					return truePredicate()
+				}
+				ret = append(ret, alternative{vals: keyValues{ref: val}})
+			}
+
+			tracelog.InfoLogger.Printf("parsed %d vals for column %s", len(ret), sqlparser.String(col))
+			return ret
+		case sqlparser.LessThanStr, sqlparser.LessEqualStr, sqlparser.GreaterThanStr, sqlparser.GreaterEqualStr:
+			op := texpr.Operator
+			if _, ok := texpr.Left.(*sqlparser.ColName); !ok {
+				// val < col is col > val
+				op = flipOperator[op]
+			}
+
+			col, val, ok := qr.columnValue(texpr, rctx)
+			if !ok {
+				return truePredicate()
+			}
+
+			bound := &keyBound{
+				val:       val,
+				inclusive: op == sqlparser.LessEqualStr || op == sqlparser.GreaterEqualStr,
 			}
+
+			var interval keyInterval
+			if op == sqlparser.LessThanStr || op == sqlparser.LessEqualStr {
+				interval.upper = bound
+			} else {
+				interval.lower = bound
+			}
+
+			return intervalPredicate(rctx.resolveColumn(col), interval)
+		default:
+		}
+	case *sqlparser.RangeCond:
+		if texpr.Operator != sqlparser.BetweenStr {
+			return truePredicate()
+		}
+
+		col, ok := texpr.Left.(*sqlparser.ColName)
+		if !ok {
+			return truePredicate()
+		}
+		from, ok := qr.exprValue(texpr.From, rctx)
+		if !ok {
+			return truePredicate()
+		}
+		to, ok := qr.exprValue(texpr.To, rctx)
+		if !ok {
+			return truePredicate()
 		}
-	case *sqlparser.ColName:
-		_, ok := qr.ColumnMapping[texpr.Name.String()]
-		return ok
+
+		return intervalPredicate(rctx.resolveColumn(col), keyInterval{
+			lower: &keyBound{val: from, inclusive: true},
+			upper: &keyBound{val: to, inclusive: true},
+		})
 	default:
 	}
 
+	return truePredicate()
+}
+
+var flipOperator = map[string]string{
+	sqlparser.LessThanStr:     sqlparser.GreaterThanStr,
+	sqlparser.LessEqualStr:    sqlparser.GreaterEqualStr,
+	sqlparser.GreaterThanStr:  sqlparser.LessThanStr,
+	sqlparser.GreaterEqualStr: sqlparser.LessEqualStr,
+}
+
+// joinPredicate gathers predicates of INNER JOIN ... ON clauses.
+// outer join conditions do not restrict rows of preserved relation
+func (qr *ProxyRouter) joinPredicate(from sqlparser.TableExprs, rctx *routingContext) predicate {
+	ret := truePredicate()
+
+	for _, texpr := range from {
+		switch tbltype := texpr.(type) {
+		case *sqlparser.ParenTableExpr:
+			ret = ret.and(qr.joinPredicate(tbltype.Exprs, rctx))
+		case *sqlparser.JoinTableExpr:
+			if tbltype.Join != sqlparser.JoinStr && tbltype.Join != sqlparser.StraightJoinStr {
+				continue
+			}
+
+			ret = ret.and(qr.joinPredicate(sqlparser.TableExprs{tbltype.LeftExpr, tbltype.RightExpr}, rctx))
+			if tbltype.On != nil {
+				ret = ret.and(qr.predicateOf(tbltype.On, rctx))
+			}
+		default:
+		}
+	}
+
+	return ret
+}
+
+// ruleApplies checks if rule is defined for one of query relations
+func ruleApplies(rule *shrule.ShardingRule, rctx *routingContext) bool {
+	if rule.TableName() == "" {
+		return true
+	}
+
+	_, ok := rctx.tables[rule.TableName()]
+	return ok
+}
+
+// shardingKey builds sharding key of rule, if every rule column is bound
+func shardingKey(rule *shrule.ShardingRule, vals keyValues) ([]byte, bool) {
+	components := make([][]byte, 0, len(rule.Columns()))
+
+	for _, col := range rule.Columns() {
+		val, ok := vals.value(rule.TableName(), col)
+		if !ok {
+			return nil, false
+		}
+
+		hashed, err := hashfunction.ApplyHashFunction(rule.HashFunction(), val)
+		if err != nil {
+			tracelog.InfoLogger.PrintError(err)
+			return nil, false
+		}
+		components = append(components, hashed)
+	}
+
+	return kr.TupleKey(components), true
+}
+
+// isShardedQuery reports whether any of query relations may be distributed
+// by sharding rules
+func (qr *ProxyRouter) isShardedQuery(rctx *routingContext) bool {
+	rules, _ := qr.ListShardingRules(context.TODO())
+
+	for _, rule := range rules {
+		if ruleApplies(rule, rctx) {
+			return true
+		}
+	}
+
 	return false
 }
 
-func (qr *ProxyRouter) routeByExpr(expr sqlparser.Expr) *ShardRoute {
+// keyRangesOf returns key ranges, which may contain rows of predicate
+// alternative. Result is not ok, if alternative does not restrict
+// sharding key of rule
+func keyRangesOf(rule *shrule.ShardingRule, alt alternative, krs []*kr.KeyRange) ([]*kr.KeyRange, bool) {
+	if key, ok := shardingKey(rule, alt.vals); ok {
+		for _, keyRange := range krs {
+			tracelog.InfoLogger.Printf("comparing %s with key range %s %s of type %s", key, keyRange.LowerBound, keyRange.UpperBound, keyRange.KeyType)
+			if ok, err := keyRange.Contains(len(rule.Columns()), key); err != nil {
+				// key is not a valid value of key range type
+				tracelog.InfoLogger.PrintError(err)
+			} else if ok {
+				return []*kr.KeyRange{keyRange}, true
+			}
+		}
+		return nil, true
+	}
 
-	switch texpr := expr.(type) {
-	case *sqlparser.AndExpr:
-		lft := qr.routeByExpr(texpr.Left)
-		if lft.Shkey.Name == NOSHARD {
-			return qr.routeByExpr(texpr.Right)
+	// intervals of hashed or composite key do not map to key ranges
+	if len(rule.Columns()) != 1 || (rule.HashFunction() != "" && rule.HashFunction() != hashfunction.HashFunctionIdent) {
+		return nil, false
+	}
+
+	intervals := alt.intervalsOf(rule.TableName(), rule.Columns()[0])
+	if len(intervals) == 0 {
+		return nil, false
+	}
+
+	var ret []*kr.KeyRange
+	for _, keyRange := range krs {
+		if overlapsAll(keyRange, intervals) {
+			ret = append(ret, keyRange)
+		}
+	}
+
+	return ret, true
+}
+
+// routeByPredicate routes query by first sharding rule, which key is restricted
+// by query predicate. Alternatives, which match no key range, select no rows, so they
+// are not routed, unless every alternative is routed, e.g. as inserted row.
+// MatchShardError is returned, if no key range is matched
+func (qr *ProxyRouter) routeByPredicate(rctx *routingContext, everyAlt bool) ([]*ShardRoute, *shrule.ShardingRule, error) {
+	rules, _ := qr.ListShardingRules(context.TODO())
+	krs, _ := qr.ListKeyRanges(context.TODO())
+
+	for _, rule := range rules {
+		if !ruleApplies(rule, rctx) {
+			continue
+		}
+
+		var matched []*kr.KeyRange
+		usable := true
+
+		for _, alt := range rctx.pred {
+			altkrs, ok := keyRangesOf(rule, alt, krs)
+			if !ok {
+				usable = false
+				break
+			}
+			if len(altkrs) == 0 && everyAlt {
+				return nil, rule, MatchShardError
+			}
+			matched = append(matched, altkrs...)
 		}
-		return lft
-	case *sqlparser.ComparisonExpr:
 
-		if qr.matchShkey(texpr.Left) {
-			tracelog.InfoLogger.Printf("go right")
-			shindx := qr.routeByExpr(texpr.Right)
-			return shindx
+		if !usable {
+			continue
+		}
+
+		if len(matched) == 0 {
+			return nil, rule, MatchShardError
+		}
+
+		routes := routesOf(matched, rctx.rw)
+		for _, route := range routes {
+			route.Rule = rule
+		}
+
+		return routes, rule, nil
+	}
+
+	return nil, nil, nil
+}
+
+// routesOf returns deduplicated shard routes of key ranges.
+// Read-only routes may be executed on replicas
+func routesOf(krs []*kr.KeyRange, rw bool) []*ShardRoute {
+	var ret []*ShardRoute
+
+	for _, keyRange := range krs {
+		dup := false
+		for _, route := range ret {
+			if route.Shkey.Name == keyRange.ShardID {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			continue
 		}
-		tracelog.InfoLogger.Printf("go left")
-		return qr.routeByExpr(texpr.Left)
-	case *sqlparser.SQLVal:
 
-		tracelog.InfoLogger.Printf("parsed val %d", texpr.Val)
-		keyRange := qr.routeByIndx(texpr.Val)
 		//rw := qr.qdb.Check(keyRange.ToSQL())
 
-		return &ShardRoute{
+		ret = append(ret, &ShardRoute{
 			Shkey: kr.ShardKey{
 				Name: keyRange.ShardID,
-				RW:   false,
+				RW:   rw,
 			},
 			Matchedkr: keyRange,
-		}
-	default:
+		})
 	}
 
-	return &ShardRoute{
-		Shkey: kr.ShardKey{
-			Name: NOSHARD,
-		},
-	}
+	return ret
 }
 
 func (qr *ProxyRouter) isLocalTbl(from sqlparser.TableExprs) bool {
@@ -327,49 +678,144 @@ func (qr *ProxyRouter) isLocalTbl(from sqlparser.TableExprs) bool {
 	return false
 }
 
-func (qr *ProxyRouter) matchShards(qstmt sqlparser.Statement) []*ShardRoute {
+// routeUnion routes UNION, if every its SELECT is routed to the same datashard.
+// Rows of scattered UNION would not be deduplicated, so it is not routed otherwise
+func (qr *ProxyRouter) routeUnion(stmt *sqlparser.Union, rctx *routingContext) ([]*ShardRoute, error) {
+	var routes []*ShardRoute
+
+	for _, sel := range []sqlparser.SelectStatement{stmt.Left, stmt.Right} {
+		for {
+			paren, ok := sel.(*sqlparser.ParenSelect)
+			if !ok {
+				break
+			}
+			sel = paren.Select
+		}
+
+		// every SELECT has own relations and predicate
+		selctx := newRoutingContext(nil)
+		selctx.params, selctx.formats, selctx.types, selctx.extended = rctx.params, rctx.formats, rctx.types, rctx.extended
+
+		selroutes, err := qr.matchShards(sel, selctx)
+		for name := range selctx.tables {
+			rctx.tables[name] = struct{}{}
+		}
+		if err != nil || len(selroutes) != 1 {
+			return nil, err
+		}
+		if routes != nil && routes[0].Shkey.Name != selroutes[0].Shkey.Name {
+			return nil, nil
+		}
+		routes = selroutes
+	}
+
+	return routes, nil
+}
+
+func (qr *ProxyRouter) matchShards(qstmt sqlparser.Statement, rctx *routingContext) ([]*ShardRoute, error) {
 
 	tracelog.InfoLogger.Printf("parsed qtype %T", qstmt)
 
 	switch stmt := qstmt.(type) {
 	case *sqlparser.Select:
 		if qr.isLocalTbl(stmt.From) {
-			return nil
+			return nil, nil
 		}
+		rctx.addTableExprs(stmt.From)
+		rctx.pred = qr.joinPredicate(stmt.From, rctx)
+		// rows of SELECT ... FOR UPDATE are locked on primary
+		rctx.rw = stmt.Lock != "" || callsWriteFunc(stmt)
+
 		if stmt.Where != nil {
-			shroute := qr.routeByExpr(stmt.Where.Expr)
-			if shroute.Shkey.Name == NOSHARD {
-				return nil
-			}
-			return []*ShardRoute{shroute}
+			rctx.pred = rctx.pred.and(qr.predicateOf(stmt.Where.Expr, rctx))
 		}
-		return nil
 
-	case *sqlparser.Insert:
-		for i, c := range stmt.Columns {
+		routes, rule, err := qr.routeByPredicate(rctx, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if rctx.merge, err = qr.planMerge(stmt, routes, rctx); err != nil {
+			return nil, err
+		}
+
+		if stmt.Where != nil {
+			qr.rewriteInLists(stmt, stmt.Where.Expr, rule, routes, rctx)
+		}
+		return routes, nil
+
+	case *sqlparser.Union:
+		routes, err := qr.routeUnion(stmt, rctx)
+		if err != nil {
+			return nil, err
+		}
+
+		rctx.rw = stmt.Lock != "" || callsWriteFunc(stmt)
+		for _, route := range routes {
+			route.Shkey.RW = rctx.rw
+		}
+		return routes, nil
 
-			if _, ok := qr.ColumnMapping[c.String()]; ok {
+	case *sqlparser.Insert:
+		switch vals := stmt.Rows.(type) {
+		case sqlparser.Values:
+			tableName := stmt.Table.Name.String()
+			rctx.addTable(tableName, "")
+			rctx.rw = true
+
+			filled, err := qr.fillSequences(stmt, vals, rctx)
+			if err != nil {
+				return nil, err
+			}
 
-				switch vals := stmt.Rows.(type) {
-				case sqlparser.Values:
-					valTyp := vals[0]
-					shroute := qr.routeByExpr(valTyp[i])
-					if shroute.Shkey.Name == NOSHARD {
-						return nil
+			// every VALUES row is an alternative of routing predicate
+			rctx.pred = make(predicate, 0, len(vals))
+			for _, valTyp := range vals {
+				row := keyValues{}
+				for i, c := range stmt.Columns {
+					if i >= len(valTyp) {
+						break
+					}
+					if val, ok := qr.exprValue(valTyp[i], rctx); ok {
+						row[columnRef{table: tableName, name: c.String()}] = val
 					}
-					return []*ShardRoute{shroute}
 				}
+				rctx.pred = append(rctx.pred, alternative{vals: row})
 			}
+
+			// every row is inserted into some datashard
+			routes, rule, err := qr.routeByPredicate(rctx, true)
+			if err != nil {
+				return nil, err
+			}
+			if len(routes) > 1 && !qr.splitInsert(stmt, vals, rule, routes, rctx) {
+				return nil, SplitInsertError
+			}
+
+			if filled && len(routes) == 1 {
+				q, ok := deparse(stmt)
+				if !ok {
+					return nil, SequenceError
+				}
+				routes[0].Query = q
+			}
+			return routes, nil
 		}
 	case *sqlparser.Update:
+		rctx.addTableExprs(stmt.TableExprs)
+		rctx.rw = true
+
 		if stmt.Where != nil {
-			shroute := qr.routeByExpr(stmt.Where.Expr)
-			if shroute.Shkey.Name == NOSHARD {
-				return nil
+			rctx.pred = qr.predicateOf(stmt.Where.Expr, rctx)
+
+			routes, rule, err := qr.routeByPredicate(rctx, false)
+			if err != nil {
+				return nil, err
 			}
-			return []*ShardRoute{shroute}
+			qr.rewriteInLists(stmt, stmt.Where.Expr, rule, routes, rctx)
+			return routes, nil
 		}
-		return nil
+		return nil, nil
 	case *sqlparser.CreateTable:
 		tracelog.InfoLogger.Printf("ddl routing excpands to every datashard")
 		// route ddl to every datashard
@@ -385,38 +831,106 @@ func (qr *ProxyRouter) matchShards(qstmt sqlparser.Statement) []*ShardRoute {
 				})
 		}
 
-		return ret
+		return ret, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 var ParseError = xerrors.New("parsing stmt error")
 
+// routeDelete routes DELETE by its WHERE clause, the same way as UPDATE.
+// DELETE without sharding key predicate is executed according to configured policy
+func (qr *ProxyRouter) routeDelete(stmt *sqlparser.Delete, rctx *routingContext) (RoutingState, error) {
+	rctx.addTableExprs(stmt.TableExprs)
+	rctx.rw = true
+
+	if stmt.Where != nil {
+		rctx.pred = qr.predicateOf(stmt.Where.Expr, rctx)
+
+		routes, rule, err := qr.routeByPredicate(rctx, false)
+		if err != nil {
+			return nil, err
+		}
+		if routes != nil {
+			qr.rewriteInLists(stmt, stmt.Where.Expr, rule, routes, rctx)
+			return ShardMatchState{
+				Routes: routes,
+			}, nil
+		}
+	}
+
+	if !qr.isShardedQuery(rctx) {
+		// relations without sharding rules live on world shard
+		return WolrdRouteState{}, nil
+	}
+
+	switch policy := config.RouterConfig().QRouterCfg.DeleteNoShardingKeyPolicy; policy {
+	case config.NoShardingKeyBroadcast:
+		return ShardMatchState{
+			Routes: qr.DataShardsRoutes(),
+		}, nil
+	case config.NoShardingKeyWorld:
+		return WolrdRouteState{}, nil
+	case config.NoShardingKeyReject, "":
+		return nil, NoShardingKeyError
+	default:
+		return nil, xerrors.Errorf("unknown no sharding key policy %v", policy)
+	}
+}
+
 func (qr *ProxyRouter) Route(q string) (RoutingState, error) {
+	return qr.RouteWithParams(q, nil)
+}
+
+func (qr *ProxyRouter) RouteWithParams(q string, bind *BindParams) (RoutingState, error) {
+	return qr.RouteWithHints(q, bind, Hints{})
+}
+
+func (qr *ProxyRouter) routeWithParams(q string, bind *BindParams) (RoutingState, error) {
 	tracelog.InfoLogger.Printf("routing by %s", q)
 
-	parsedStmt, err := sqlparser.Parse(q)
+	if isCopyStmt(q) {
+		stmt, err := parseCopy(q)
+		if err != nil {
+			return nil, ParseError
+		}
+		return qr.routeCopy(stmt)
+	}
+
+	rctx := newRoutingContext(bind)
+
+	parsedStmt, err := sqlparser.Parse(rewritePlaceholders(q))
 	if err != nil {
 		return nil, ParseError
 	}
 
 	tracelog.InfoLogger.Printf("stmt type %T", parsedStmt)
 
-	switch parsedStmt.(type) {
+	switch stmt := parsedStmt.(type) {
 	case *sqlparser.DDL:
 		return ShardMatchState{
 			Routes: qr.DataShardsRoutes(),
 		}, nil
+	case *sqlparser.Delete:
+		return qr.routeDelete(stmt, rctx)
 	default:
-		routes := qr.matchShards(parsedStmt)
+		routes, err := qr.matchShards(parsedStmt, rctx)
+		if err != nil {
+			return nil, err
+		}
 
 		if routes == nil {
+			// relations without sharding rules live on world shard
+			if len(rctx.tables) > 0 && !qr.isShardedQuery(rctx) {
+				return WolrdRouteState{}, nil
+			}
 			return SkipRoutingState{}, nil
 		}
 
 		return ShardMatchState{
 			Routes: routes,
+			Merge:  rctx.merge,
 		}, nil
 	}
 
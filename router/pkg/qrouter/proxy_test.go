@@ -0,0 +1,371 @@
+package qrouter
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+)
+
+// newTestRouter creates router with key ranges [1, 11) on sh1 and [11, 21) on sh2
+// of integer column id of every relation
+func newTestRouter(t *testing.T) *ProxyRouter {
+	t.Helper()
+
+	return newTestRouterWith(t, shrule.NewShardingRule("r1", "", []string{"id"}, ""),
+		&kr.KeyRange{ID: "k1", ShardID: "sh1", LowerBound: []byte("1"), UpperBound: []byte("11"), KeyType: kr.KeyTypeInteger},
+		&kr.KeyRange{ID: "k2", ShardID: "sh2", LowerBound: []byte("11"), UpperBound: []byte("21"), KeyType: kr.KeyTypeInteger},
+	)
+}
+
+func newTestRouterWith(t *testing.T, rule *shrule.ShardingRule, krs ...*kr.KeyRange) *ProxyRouter {
+	t.Helper()
+
+	qr, err := NewProxyRouter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := qr.AddShardingRule(context.TODO(), rule); err != nil {
+		t.Fatal(err)
+	}
+	for _, keyRange := range krs {
+		if err := qr.AddKeyRange(context.TODO(), keyRange); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return qr
+}
+
+// routedShards returns sorted datashards of routing state and queries, rewritten for them
+func routedShards(t *testing.T, state RoutingState) ([]string, map[string]string) {
+	t.Helper()
+
+	v, ok := state.(ShardMatchState)
+	if !ok {
+		t.Fatalf("unexpected routing state %T", state)
+	}
+
+	var shards []string
+	queries := map[string]string{}
+	for _, route := range v.Routes {
+		shards = append(shards, route.Shkey.Name)
+		if route.Query != "" {
+			queries[route.Shkey.Name] = route.Query
+		}
+	}
+	sort.Strings(shards)
+
+	return shards, queries
+}
+
+func TestRoute(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		query   string
+		shards  []string
+		queries map[string]string
+		// query is not routed by key ranges
+		skip bool
+		err  error
+	}{
+		{
+			name:   "equality",
+			query:  "SELECT * FROM t WHERE id = 5",
+			shards: []string{"sh1"},
+		},
+		{
+			name:   "in list with value outside key ranges",
+			query:  "SELECT * FROM t WHERE id IN (1, 999)",
+			shards: []string{"sh1"},
+		},
+		{
+			name:   "in list of several datashards",
+			query:  "SELECT * FROM t WHERE id IN (1, 15)",
+			shards: []string{"sh1", "sh2"},
+		},
+		{
+			name:   "or",
+			query:  "SELECT * FROM t WHERE id = 2 OR id = 12",
+			shards: []string{"sh1", "sh2"},
+		},
+		{
+			name:  "or with unrestricted alternative",
+			query: "SELECT * FROM t WHERE id = 2 OR name = 'x'",
+			skip:  true,
+		},
+		{
+			name:   "range",
+			query:  "SELECT * FROM t WHERE id >= 11 AND id < 15",
+			shards: []string{"sh2"},
+		},
+		{
+			name:   "range of several datashards",
+			query:  "SELECT * FROM t WHERE id BETWEEN 5 AND 15",
+			shards: []string{"sh1", "sh2"},
+		},
+		{
+			name:   "union of single datashard",
+			query:  "SELECT * FROM t WHERE id = 2 UNION (SELECT * FROM t WHERE id = 3)",
+			shards: []string{"sh1"},
+		},
+		{
+			name:  "union of several datashards",
+			query: "SELECT * FROM t WHERE id = 2 UNION SELECT * FROM t WHERE id = 15",
+			skip:  true,
+		},
+		{
+			name:  "union with unrestricted select",
+			query: "SELECT * FROM t WHERE id = 2 UNION SELECT * FROM t",
+			skip:  true,
+		},
+		{
+			name:  "value outside key ranges",
+			query: "SELECT * FROM t WHERE id = 999",
+			err:   MatchShardError,
+		},
+		{
+			name:  "insert of row outside key ranges",
+			query: "INSERT INTO t (id) VALUES (1), (999)",
+			err:   MatchShardError,
+		},
+		{
+			name:   "insert",
+			query:  "INSERT INTO t (id, name) VALUES (12, 'x')",
+			shards: []string{"sh2"},
+		},
+		{
+			name:   "insert of several datashards",
+			query:  "INSERT INTO t (id, name) VALUES (1, 'a'), (15, 'b'), (2, 'c')",
+			shards: []string{"sh1", "sh2"},
+			queries: map[string]string{
+				"sh1": "insert into t(id, name) values (1, 'a'), (2, 'c')",
+				"sh2": "insert into t(id, name) values (15, 'b')",
+			},
+		},
+		{
+			name:   "update",
+			query:  "UPDATE t SET name = 'x' WHERE id = 3",
+			shards: []string{"sh1"},
+		},
+		{
+			name:   "delete",
+			query:  "DELETE FROM t WHERE id = 15",
+			shards: []string{"sh2"},
+		},
+		{
+			name:  "delete outside key ranges",
+			query: "DELETE FROM t WHERE id = 999",
+			err:   MatchShardError,
+		},
+		{
+			name:  "delete of in list outside key ranges",
+			query: "DELETE FROM t WHERE id IN (999, 1000)",
+			err:   MatchShardError,
+		},
+		{
+			name:   "delete of in list of several datashards",
+			query:  "DELETE FROM t WHERE id IN (1, 15, 999)",
+			shards: []string{"sh1", "sh2"},
+			queries: map[string]string{
+				"sh1": "delete from t where id in (1)",
+				"sh2": "delete from t where id in (15)",
+			},
+		},
+		{
+			name:  "delete without sharding key",
+			query: "DELETE FROM t WHERE name = 'x'",
+			err:   NoShardingKeyError,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := newTestRouter(t)
+
+			state, err := qr.Route(tt.query)
+			if tt.err != nil {
+				if !xerrors.Is(err, tt.err) {
+					t.Fatalf("Route(%q) error = %v, want %v", tt.query, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Route(%q) error = %v", tt.query, err)
+			}
+			if tt.skip {
+				if _, ok := state.(SkipRoutingState); !ok {
+					t.Fatalf("Route(%q) state = %T, want SkipRoutingState", tt.query, state)
+				}
+				return
+			}
+
+			shards, queries := routedShards(t, state)
+			if !reflect.DeepEqual(shards, tt.shards) {
+				t.Errorf("Route(%q) shards = %v, want %v", tt.query, shards, tt.shards)
+			}
+			if tt.queries != nil && !reflect.DeepEqual(queries, tt.queries) {
+				t.Errorf("Route(%q) queries = %v, want %v", tt.query, queries, tt.queries)
+			}
+		})
+	}
+}
+
+func TestRouteKeyWithDelimiter(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		rule   *shrule.ShardingRule
+		query  string
+		shards []string
+	}{
+		{
+			name:   "single column key",
+			rule:   shrule.NewShardingRule("r1", "t", []string{"name"}, ""),
+			query:  "SELECT * FROM t WHERE name = 'a,b'",
+			shards: []string{"sh1"},
+		},
+		{
+			name:   "composite key",
+			rule:   shrule.NewShardingRule("r1", "t", []string{"name", "tag"}, ""),
+			query:  "SELECT * FROM t WHERE name = 'a' AND tag = 'b'",
+			shards: []string{"sh2"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := newTestRouterWith(t, tt.rule,
+				&kr.KeyRange{ID: "k1", ShardID: "sh1", LowerBound: []byte("a+"), UpperBound: []byte("b"), KeyType: kr.KeyTypeVarchar},
+				&kr.KeyRange{ID: "k2", ShardID: "sh2", LowerBound: []byte("a"), UpperBound: []byte("a+"), KeyType: kr.KeyTypeVarchar},
+			)
+
+			state, err := qr.Route(tt.query)
+			if err != nil {
+				t.Fatalf("Route(%q) error = %v", tt.query, err)
+			}
+
+			if shards, _ := routedShards(t, state); !reflect.DeepEqual(shards, tt.shards) {
+				t.Errorf("Route(%q) shards = %v, want %v", tt.query, shards, tt.shards)
+			}
+		})
+	}
+}
+
+func TestRouteWithParams(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		query  string
+		bind   *BindParams
+		shards []string
+		// rows of datashards are merged by router
+		merge bool
+		err   error
+	}{
+		{
+			name:   "text parameter",
+			query:  "SELECT * FROM t WHERE id = $1",
+			bind:   &BindParams{Values: [][]byte{[]byte("15")}},
+			shards: []string{"sh2"},
+		},
+		{
+			name:  "binary parameter",
+			query: "SELECT * FROM t WHERE id = $1",
+			bind: &BindParams{
+				Values:  [][]byte{{0, 0, 0, 3}},
+				Formats: []int16{binaryFormat},
+				Types:   []uint32{int4OID},
+			},
+			shards: []string{"sh1"},
+		},
+		{
+			name:   "aggregate of several datashards",
+			query:  "SELECT count(*), max(v) FROM t WHERE id IN ($1, $2)",
+			bind:   &BindParams{Values: [][]byte{[]byte("1"), []byte("15")}},
+			shards: []string{"sh1", "sh2"},
+			merge:  true,
+		},
+		{
+			name:  "average of several datashards",
+			query: "SELECT avg(v) FROM t WHERE id IN ($1, $2)",
+			bind:  &BindParams{Values: [][]byte{[]byte("1"), []byte("15")}},
+			err:   AggregateError,
+		},
+		{
+			name:   "insert of single datashard",
+			query:  "INSERT INTO t (id) VALUES ($1), ($2)",
+			bind:   &BindParams{Values: [][]byte{[]byte("1"), []byte("2")}},
+			shards: []string{"sh1"},
+		},
+		{
+			name:  "insert of several datashards",
+			query: "INSERT INTO t (id) VALUES ($1), ($2)",
+			bind:  &BindParams{Values: [][]byte{[]byte("1"), []byte("15")}},
+			err:   SplitInsertError,
+		},
+		{
+			name:  "insert of row outside key ranges",
+			query: "INSERT INTO t (id) VALUES ($1)",
+			bind:  &BindParams{Values: [][]byte{[]byte("999")}},
+			err:   MatchShardError,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := newTestRouter(t)
+
+			state, err := qr.RouteWithParams(tt.query, tt.bind)
+			if tt.err != nil {
+				if !xerrors.Is(err, tt.err) {
+					t.Fatalf("RouteWithParams(%q) error = %v, want %v", tt.query, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RouteWithParams(%q) error = %v", tt.query, err)
+			}
+
+			shards, _ := routedShards(t, state)
+			if !reflect.DeepEqual(shards, tt.shards) {
+				t.Errorf("RouteWithParams(%q) shards = %v, want %v", tt.query, shards, tt.shards)
+			}
+			if merge := state.(ShardMatchState).Merge != nil; merge != tt.merge {
+				t.Errorf("RouteWithParams(%q) rows are merged = %v, want %v", tt.query, merge, tt.merge)
+			}
+		})
+	}
+}
+
+func TestRouteReadWrite(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		rw    bool
+	}{
+		{query: "SELECT * FROM t WHERE id = 5", rw: false},
+		{query: "SELECT * FROM t WHERE id = 5 FOR UPDATE", rw: true},
+		{query: "SELECT nextval('s') FROM t WHERE id = 5", rw: true},
+		{query: "SELECT setval('s', id) FROM t WHERE id = 5", rw: true},
+		{query: "SELECT * FROM t WHERE id = 2 UNION SELECT * FROM t WHERE id = 3", rw: false},
+		{query: "SELECT id FROM t WHERE id = 2 UNION SELECT nextval('s') FROM t WHERE id = 3", rw: true},
+		{query: "SELECT id FROM t WHERE id = 2 UNION SELECT setval('s', 1) FROM t WHERE id = 3", rw: true},
+		{query: "UPDATE t SET name = 'x' WHERE id = 3", rw: true},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			qr := newTestRouter(t)
+
+			state, err := qr.Route(tt.query)
+			if err != nil {
+				t.Fatalf("Route(%q) error = %v", tt.query, err)
+			}
+			v, ok := state.(ShardMatchState)
+			if !ok {
+				t.Fatalf("Route(%q) state = %T, want ShardMatchState", tt.query, state)
+			}
+			for _, route := range v.Routes {
+				if route.Shkey.RW != tt.rw {
+					t.Errorf("Route(%q) of %s RW = %v, want %v", tt.query, route.Shkey.Name, route.Shkey.RW, tt.rw)
+				}
+			}
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"github.com/pg-sharding/spqr/pkg/config"
 	"github.com/pg-sharding/spqr/pkg/models/datashards"
 	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/sequences"
 	"github.com/pg-sharding/spqr/pkg/models/shrule"
+	"github.com/pg-sharding/spqr/pkg/models/transactions"
 	"github.com/pg-sharding/spqr/qdb"
+	"github.com/pg-sharding/spqr/router/pkg/server"
 	"github.com/pkg/errors"
 	"golang.org/x/xerrors"
 )
@@ -17,9 +20,22 @@ const NOSHARD = ""
 type ShardRoute struct {
 	Shkey     kr.ShardKey
 	Matchedkr *kr.KeyRange
+	// sharding rule, by which key range is matched
+	Rule *shrule.ShardingRule
+
+	// Query is rewritten for this shard, if not empty
+	Query string
 }
 
 var MatchShardError = xerrors.New("failed to match datashard")
+var NoShardingKeyError = xerrors.New("statement without sharding key predicate is rejected")
+var SplitInsertError = xerrors.New("failed to split multi-row insert between datashards")
+var AggregateError = xerrors.New("aggregate is not supported for query to several datashards")
+var SequenceError = xerrors.New("failed to fill sequence column of insert")
+var LocalTopologyError = xerrors.New("topology of local router is fixed")
+var HintError = xerrors.New("failed to parse routing hint")
+var HintShardError = xerrors.New("datashard of routing hint is not found")
+var HintRuleError = xerrors.New("failed to resolve sharding key of routing hint by sharding rule")
 
 type RoutingState interface {
 	iState()
@@ -29,6 +45,8 @@ type ShardMatchState struct {
 	RoutingState
 
 	Routes []*ShardRoute
+	// merge plan of rows, if they are merged from several datashards
+	Merge *server.MergePlan
 }
 
 type SkipRoutingState struct {
@@ -42,8 +60,16 @@ type WolrdRouteState struct {
 type QueryRouter interface {
 	kr.KeyRangeMgr
 	shrule.ShardingRulesMgr
+	transactions.TransactionMgr
+	sequences.SequenceMgr
+
+	// NextVal returns next value of global sequence
+	NextVal(ctx context.Context, name string) (int64, error)
   
 	Route(q string) (RoutingState, error)
+	RouteWithParams(q string, bind *BindParams) (RoutingState, error)
+	// RouteWithHints routes query by routing hints of session and query
+	RouteWithHints(q string, bind *BindParams, hints Hints) (RoutingState, error)
 	// do not use
 	AddLocalTable(tname string) error
 
@@ -51,6 +77,7 @@ type QueryRouter interface {
 	Shards() []string
 	WorldShards() []string
 	WorldShardsRoutes() []*ShardRoute
+	DataShardsRoutes() []*ShardRoute
 
 	AddDataShard(ctx context.Context, ds *datashards.DataShard) error
 	ListDataShards(ctx context.Context) []*datashards.DataShard
@@ -65,6 +92,8 @@ func NewQrouter(qtype config.QrouterType) (QueryRouter, error) {
 		return NewLocalQrouter(config.RouterConfig().QRouterCfg.LocalShard)
 	case config.ProxyQrouter:
 		return NewProxyRouter()
+	case config.ShardQrouter:
+		return NewShardingRouter(context.Background(), config.RouterConfig().QRouterCfg.QdbAddr)
 	default:
 		return nil, errors.Errorf("unknown qrouter type: %v", config.RouterConfig().QRouterCfg.Qtype)
 	}
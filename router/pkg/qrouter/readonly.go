@@ -0,0 +1,63 @@
+package qrouter
+
+import (
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+)
+
+// writeFuncs are built-in functions, which modify data, so query, calling them, is a write
+var writeFuncs = map[string]struct{}{
+	"nextval":                   {},
+	"setval":                    {},
+	"pg_advisory_lock":          {},
+	"pg_advisory_xact_lock":     {},
+	"pg_try_advisory_lock":      {},
+	"pg_try_advisory_xact_lock": {},
+	"txid_current":              {},
+	"pg_current_xact_id":        {},
+	"pg_notify":                 {},
+}
+
+// callsWriteFunc checks if statement calls built-in function, which modifies data
+func callsWriteFunc(stmt sqlparser.Statement) bool {
+	found := false
+
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if fexpr, ok := node.(*sqlparser.FuncExpr); ok {
+			_, found = writeFuncs[fexpr.Name.Lowered()]
+		}
+		return !found, nil
+	}, stmt)
+
+	return found
+}
+
+// ReadOnly checks if query only reads data, so it may be executed on replica.
+// Queries, which are not parsed by router, are considered writes. Router does not know,
+// if user-defined function modifies data, so SELECT, calling it, is considered read-only.
+// Such queries are routed to primary by routing hint target_session_attrs=read-write
+func ReadOnly(q string) bool {
+	if _, stripped, err := parseHintComment(q); err == nil {
+		q = stripped
+	}
+
+	if isCopyStmt(q) {
+		stmt, err := parseCopy(q)
+		return err == nil && !stmt.From
+	}
+
+	parsedStmt, err := sqlparser.Parse(rewritePlaceholders(q))
+	if err != nil {
+		return false
+	}
+
+	switch stmt := parsedStmt.(type) {
+	case *sqlparser.Select:
+		return stmt.Lock == "" && !callsWriteFunc(stmt)
+	case *sqlparser.Union:
+		return stmt.Lock == "" && !callsWriteFunc(stmt)
+	case *sqlparser.Show:
+		return true
+	default:
+		return false
+	}
+}
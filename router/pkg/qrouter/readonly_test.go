@@ -0,0 +1,30 @@
+package qrouter
+
+import "testing"
+
+func TestReadOnly(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  bool
+	}{
+		{query: "SELECT * FROM t WHERE id = 1", want: true},
+		{query: "SELECT * FROM t WHERE id = $1", want: true},
+		{query: "SELECT 1 UNION SELECT 2", want: true},
+		{query: "/* spqr: sharding_key=1 */ SELECT * FROM t", want: true},
+		{query: "SELECT my_fn()", want: true},
+		{query: "SELECT * FROM t FOR UPDATE", want: false},
+		{query: "SELECT nextval('s')", want: false},
+		{query: "SELECT id, pg_advisory_lock(id) FROM t", want: false},
+		{query: "SELECT 1 UNION SELECT setval('s', 1)", want: false},
+		{query: "INSERT INTO t (id) VALUES (1)", want: false},
+		{query: "UPDATE t SET v = 1", want: false},
+		{query: "DELETE FROM t", want: false},
+		{query: "COPY t TO STDOUT", want: true},
+		{query: "COPY t FROM STDIN", want: false},
+		{query: "VACUUM t", want: false},
+	} {
+		if got := ReadOnly(tt.query); got != tt.want {
+			t.Errorf("ReadOnly(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
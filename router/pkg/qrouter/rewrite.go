@@ -0,0 +1,157 @@
+package qrouter
+
+import (
+	"strings"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/pkg/models/shrule"
+)
+
+// deparse renders statement back to SQL text. Parser is MySQL flavoured,
+// so statements, rendered with syntax unknown to PostgreSQL
+// (quoted identifiers, escaped strings, LIMIT offset, count), are not rewritten
+func deparse(stmt sqlparser.Statement) (string, bool) {
+	if sel, ok := stmt.(*sqlparser.Select); ok && sel.Limit != nil && sel.Limit.Offset != nil {
+		return "", false
+	}
+
+	q := sqlparser.String(stmt)
+	if strings.ContainsAny(q, "`\\") {
+		return "", false
+	}
+
+	return q, true
+}
+
+// inListOf finds col IN (...) comparison on rule column
+// among conjuncts of query predicate
+func inListOf(expr sqlparser.Expr, rule *shrule.ShardingRule, rctx *routingContext) *sqlparser.ComparisonExpr {
+	switch texpr := expr.(type) {
+	case *sqlparser.AndExpr:
+		if ret := inListOf(texpr.Left, rule, rctx); ret != nil {
+			return ret
+		}
+		return inListOf(texpr.Right, rule, rctx)
+	case *sqlparser.ParenExpr:
+		return inListOf(texpr.Expr, rule, rctx)
+	case *sqlparser.ComparisonExpr:
+		if texpr.Operator != sqlparser.InStr {
+			return nil
+		}
+		col, ok := texpr.Left.(*sqlparser.ColName)
+		if !ok {
+			return nil
+		}
+		if _, ok := texpr.Right.(sqlparser.ValTuple); !ok {
+			return nil
+		}
+
+		ref := rctx.resolveColumn(col)
+		if ref.name != rule.Columns()[0] {
+			return nil
+		}
+		if rule.TableName() != "" && ref.table != rule.TableName() {
+			return nil
+		}
+
+		return texpr
+	default:
+		return nil
+	}
+}
+
+// rewriteInLists rewrites scattered query, so every shard receives
+// only its own elements of IN list on sharding column
+func (qr *ProxyRouter) rewriteInLists(stmt sqlparser.Statement, where sqlparser.Expr, rule *shrule.ShardingRule, routes []*ShardRoute, rctx *routingContext) {
+	if len(routes) < 2 || rule == nil || len(rule.Columns()) != 1 || rctx.extended {
+		return
+	}
+
+	inExpr := inListOf(where, rule, rctx)
+	if inExpr == nil {
+		return
+	}
+
+	tuple := inExpr.Right.(sqlparser.ValTuple)
+	defer func() {
+		inExpr.Right = tuple
+	}()
+
+	ref := rctx.resolveColumn(inExpr.Left.(*sqlparser.ColName))
+	shardTuples := map[string]sqlparser.ValTuple{}
+
+	for _, elem := range tuple {
+		val, ok := qr.exprValue(elem, rctx)
+		if !ok {
+			return
+		}
+		key, ok := shardingKey(rule, keyValues{ref: val})
+		if !ok {
+			return
+		}
+		keyRange := qr.routeByIndx(len(rule.Columns()), key)
+		shardTuples[keyRange.ShardID] = append(shardTuples[keyRange.ShardID], elem)
+	}
+
+	queries := make([]string, len(routes))
+
+	for i, route := range routes {
+		shardTuple, ok := shardTuples[route.Shkey.Name]
+		if !ok {
+			return
+		}
+
+		inExpr.Right = shardTuple
+		if queries[i], ok = deparse(stmt); !ok {
+			return
+		}
+	}
+
+	for i, route := range routes {
+		route.Query = queries[i]
+	}
+}
+
+// splitInsert rewrites multi-row INSERT, so every shard receives
+// only rows of its own key ranges. INSERT, executed via extended
+// protocol, is not split, as rewritten queries are not relayed then
+func (qr *ProxyRouter) splitInsert(stmt *sqlparser.Insert, rows sqlparser.Values, rule *shrule.ShardingRule, routes []*ShardRoute, rctx *routingContext) bool {
+	if rule == nil || rctx.extended || len(rows) != len(rctx.pred) {
+		return false
+	}
+
+	defer func() {
+		stmt.Rows = rows
+	}()
+
+	shardRows := map[string]sqlparser.Values{}
+
+	for i, row := range rows {
+		key, ok := shardingKey(rule, rctx.pred[i].vals)
+		if !ok {
+			return false
+		}
+		keyRange := qr.routeByIndx(len(rule.Columns()), key)
+		shardRows[keyRange.ShardID] = append(shardRows[keyRange.ShardID], row)
+	}
+
+	queries := make([]string, len(routes))
+
+	for i, route := range routes {
+		values, ok := shardRows[route.Shkey.Name]
+		if !ok {
+			return false
+		}
+
+		stmt.Rows = values
+		if queries[i], ok = deparse(stmt); !ok {
+			return false
+		}
+	}
+
+	for i, route := range routes {
+		route.Query = queries[i]
+	}
+
+	return true
+}
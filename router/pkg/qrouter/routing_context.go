@@ -0,0 +1,119 @@
+package qrouter
+
+import (
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/router/pkg/server"
+)
+
+// columnRef is a column of relation. Table is empty,
+// if column reference could not be resolved unambiguously.
+type columnRef struct {
+	table string
+	name  string
+}
+
+// routingContext holds per-query routing state
+type routingContext struct {
+	// Bind message parameters, if query is executed via extended protocol
+	params  [][]byte
+	formats []int16
+	types   []uint32
+	// query is executed via extended protocol, where rewritten queries are not relayed
+	extended bool
+
+	// relation names by aliases, for relations in FROM clause
+	tableAliases map[string]string
+	tables       map[string]struct{}
+
+	// restriction of query predicates on column values
+	pred predicate
+
+	// merge plan of scattered SELECT rows
+	merge *server.MergePlan
+
+	// query modifies data, so it is executed on primary
+	rw bool
+}
+
+func newRoutingContext(bind *BindParams) *routingContext {
+	rctx := &routingContext{
+		tableAliases: map[string]string{},
+		tables:       map[string]struct{}{},
+		pred:         truePredicate(),
+	}
+
+	if bind != nil {
+		rctx.extended = true
+		rctx.params = bind.Values
+		rctx.formats = bind.Formats
+		rctx.types = bind.Types
+	}
+
+	return rctx
+}
+
+func (rctx *routingContext) addTable(name string, alias string) {
+	rctx.tables[name] = struct{}{}
+	rctx.tableAliases[name] = name
+	if alias != "" {
+		rctx.tableAliases[alias] = name
+	}
+}
+
+func (rctx *routingContext) addTableExprs(from sqlparser.TableExprs) {
+	for _, texpr := range from {
+		rctx.addTableExpr(texpr)
+	}
+}
+
+func (rctx *routingContext) addTableExpr(texpr sqlparser.TableExpr) {
+	switch tbltype := texpr.(type) {
+	case *sqlparser.ParenTableExpr:
+		rctx.addTableExprs(tbltype.Exprs)
+	case *sqlparser.JoinTableExpr:
+		rctx.addTableExpr(tbltype.LeftExpr)
+		rctx.addTableExpr(tbltype.RightExpr)
+	case *sqlparser.AliasedTableExpr:
+		switch tname := tbltype.Expr.(type) {
+		case sqlparser.TableName:
+			rctx.addTable(tname.Name.String(), tbltype.As.String())
+		case *sqlparser.Subquery:
+		default:
+		}
+	}
+}
+
+// addStatementTables adds relations, which statement reads or modifies
+func (rctx *routingContext) addStatementTables(stmt sqlparser.Statement) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		rctx.addTableExprs(stmt.From)
+	case *sqlparser.Insert:
+		rctx.addTable(stmt.Table.Name.String(), "")
+	case *sqlparser.Update:
+		rctx.addTableExprs(stmt.TableExprs)
+	case *sqlparser.Delete:
+		rctx.addTableExprs(stmt.TableExprs)
+	}
+}
+
+// resolveColumn maps column to relation by its qualifier. Unqualified column
+// belongs to the only relation of the query, if there is exactly one.
+func (rctx *routingContext) resolveColumn(col *sqlparser.ColName) columnRef {
+	ref := columnRef{
+		name: col.Name.String(),
+	}
+
+	if !col.Qualifier.IsEmpty() {
+		ref.table = rctx.tableAliases[col.Qualifier.Name.String()]
+		return ref
+	}
+
+	if len(rctx.tables) == 1 {
+		for tname := range rctx.tables {
+			ref.table = tname
+		}
+	}
+
+	return ref
+}
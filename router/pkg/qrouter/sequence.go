@@ -0,0 +1,187 @@
+package qrouter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/blastrain/vitess-sqlparser/sqlparser"
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/models/sequences"
+	protos "github.com/pg-sharding/spqr/router/protos"
+	"github.com/wal-g/tracelog"
+	"google.golang.org/grpc"
+)
+
+// nextValFunc is function, which returns next value of global sequence
+const nextValFunc = "spqr_nextval"
+
+// defaultSequenceBlockSize is number of sequence values, allocated to router at once
+const defaultSequenceBlockSize = 100
+
+// coordinatorSequences requests blocks of sequence values from coordinator
+type coordinatorSequences struct {
+	conn *grpc.ClientConn
+}
+
+func (c *coordinatorSequences) NextRange(ctx context.Context, name string, count int64) (*sequences.Range, error) {
+	reply, err := protos.NewSequenceServiceClient(c.conn).NextRange(ctx, &protos.NextRangeRequest{
+		Name:  name,
+		Count: count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sequences.RangeFromProto(reply.Range), nil
+}
+
+// sequenceCache hands out sequence values from blocks, allocated to router
+type sequenceCache struct {
+	mu sync.Mutex
+
+	mgr       sequences.SequenceMgr
+	blockSize int64
+	blocks    map[string]*sequences.Range
+}
+
+func newSequenceCache(mgr sequences.SequenceMgr, blockSize int64) *sequenceCache {
+	if blockSize <= 0 {
+		blockSize = defaultSequenceBlockSize
+	}
+
+	return &sequenceCache{
+		mgr:       mgr,
+		blockSize: blockSize,
+		blocks:    map[string]*sequences.Range{},
+	}
+}
+
+func (c *sequenceCache) nextVal(ctx context.Context, name string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block, ok := c.blocks[name]
+	if !ok || block.Count == 0 {
+		var err error
+		if block, err = c.mgr.NextRange(ctx, name, c.blockSize); err != nil {
+			return 0, err
+		}
+		c.blocks[name] = block
+	}
+
+	val := block.First
+	block.First++
+	block.Count--
+
+	return val, nil
+}
+
+// newSequenceMgr returns source of sequence blocks: coordinator, if its address is configured,
+// or fallback, which allocates blocks in qdb of router. Memory qdb fails to allocate blocks,
+// so sequences of PROXY and LOCAL routers require coordinator
+func newSequenceMgr(fallback sequences.SequenceMgr) (sequences.SequenceMgr, error) {
+	addr := config.RouterConfig().QRouterCfg.CoordinatorAddr
+	if addr == "" {
+		return fallback, nil
+	}
+
+	tracelog.InfoLogger.Printf("sequence values are allocated by coordinator %v", addr)
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	return &coordinatorSequences{conn: conn}, nil
+}
+
+// NextValCall checks if query is SELECT spqr_nextval('name'),
+// which is executed by router, and returns name of sequence
+func NextValCall(q string) (string, bool) {
+	stmt, err := sqlparser.Parse(q)
+	if err != nil {
+		return "", false
+	}
+
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 || sel.Where != nil || sel.GroupBy != nil || sel.Limit != nil {
+		return "", false
+	}
+
+	for _, texpr := range sel.From {
+		aexpr, ok := texpr.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return "", false
+		}
+		if tname, ok := aexpr.Expr.(sqlparser.TableName); !ok || tname.Name.String() != "dual" {
+			return "", false
+		}
+	}
+
+	aexpr, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return "", false
+	}
+
+	fexpr, ok := aexpr.Expr.(*sqlparser.FuncExpr)
+	if !ok || !fexpr.Name.EqualString(nextValFunc) || len(fexpr.Exprs) != 1 {
+		return "", false
+	}
+
+	arg, ok := fexpr.Exprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return "", false
+	}
+
+	val, ok := arg.Expr.(*sqlparser.SQLVal)
+	if !ok || val.Type != sqlparser.StrVal {
+		return "", false
+	}
+
+	return string(val.Val), true
+}
+
+func (qr *ProxyRouter) NextRange(ctx context.Context, name string, count int64) (*sequences.Range, error) {
+	first, err := qr.qdb.AllocateSequenceRange(ctx, name, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return sequences.NewRange(name, first, count), nil
+}
+
+func (qr *ProxyRouter) NextVal(ctx context.Context, name string) (int64, error) {
+	return qr.seqs.nextVal(ctx, name)
+}
+
+// fillSequences adds declared sequence columns, missing in INSERT, to every row
+// with next values of sequences, so rows are routed by filled values.
+// Query, executed via extended protocol, is relayed as is, so it is not filled
+func (qr *ProxyRouter) fillSequences(stmt *sqlparser.Insert, rows sqlparser.Values, rctx *routingContext) (bool, error) {
+	if rctx.extended || len(stmt.Columns) == 0 {
+		return false, nil
+	}
+
+	filled := false
+
+	for _, seq := range config.RouterConfig().QRouterCfg.Sequences {
+		if stmt.Table.Name.String() != seq.Table || stmt.Columns.FindColumn(sqlparser.NewColIdent(seq.Column)) >= 0 {
+			continue
+		}
+
+		stmt.Columns = append(stmt.Columns, sqlparser.NewColIdent(seq.Column))
+		for i := range rows {
+			val, err := qr.NextVal(context.TODO(), seq.Name)
+			if err != nil {
+				tracelog.ErrorLogger.PrintError(err)
+				return false, SequenceError
+			}
+			rows[i] = append(rows[i], sqlparser.NewIntVal([]byte(strconv.FormatInt(val, 10))))
+		}
+
+		filled = true
+	}
+
+	return filled, nil
+}
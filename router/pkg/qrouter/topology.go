@@ -0,0 +1,160 @@
+package qrouter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pg-sharding/spqr/qdb"
+	"github.com/wal-g/tracelog"
+)
+
+// topologyCache caches key ranges and sharding rules of qdb, shared by several routers,
+// so queries are routed without round trip to qdb. Cache is dropped on every change
+// of topology, made by this router or reported by qdb watch. Topology is not cached,
+// while watch is not established
+type topologyCache struct {
+	qdb.QrouterDB
+
+	mu      sync.Mutex
+	watched bool
+	// generation is incremented on every change, so stale topology is never cached
+	generation uint64
+	krs        []*qdb.KeyRange
+	rules      []*qdb.ShardingRule
+	krsValid   bool
+	rulesValid bool
+}
+
+func newTopologyCache(ctx context.Context, db qdb.QrouterDB) (*topologyCache, error) {
+	t := &topologyCache{
+		QrouterDB: db,
+	}
+
+	notifyio := make(chan interface{}, 1)
+	if err := db.WatchTopology(ctx, notifyio); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-notifyio:
+				if state, ok := msg.(*qdb.WatchState); ok {
+					tracelog.InfoLogger.Printf("topology watch is established: %v, drop cached topology", state.Established)
+					t.setWatched(state.Established)
+					continue
+				}
+
+				tracelog.InfoLogger.Printf("topology is changed, drop cached topology")
+				t.invalidate()
+			}
+		}
+	}()
+
+	return t, nil
+}
+
+// setWatched enables or disables caching. Changes may be missed,
+// while watch is not established, so cache is dropped either way
+func (t *topologyCache) setWatched(watched bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.watched = watched
+	t.dropLocked()
+}
+
+func (t *topologyCache) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.dropLocked()
+}
+
+func (t *topologyCache) dropLocked() {
+	t.generation++
+	t.krs, t.krsValid = nil, false
+	t.rules, t.rulesValid = nil, false
+}
+
+func (t *topologyCache) ListKeyRanges(ctx context.Context) ([]*qdb.KeyRange, error) {
+	t.mu.Lock()
+	if t.krsValid {
+		defer t.mu.Unlock()
+		return append([]*qdb.KeyRange{}, t.krs...), nil
+	}
+	generation := t.generation
+	t.mu.Unlock()
+
+	krs, err := t.QrouterDB.ListKeyRanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if generation == t.generation && t.watched {
+		t.krs, t.krsValid = krs, true
+	}
+
+	return append([]*qdb.KeyRange{}, krs...), nil
+}
+
+func (t *topologyCache) ListShardingRules(ctx context.Context) ([]*qdb.ShardingRule, error) {
+	t.mu.Lock()
+	if t.rulesValid {
+		defer t.mu.Unlock()
+		return append([]*qdb.ShardingRule{}, t.rules...), nil
+	}
+	generation := t.generation
+	t.mu.Unlock()
+
+	rules, err := t.QrouterDB.ListShardingRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if generation == t.generation && t.watched {
+		t.rules, t.rulesValid = rules, true
+	}
+
+	return append([]*qdb.ShardingRule{}, rules...), nil
+}
+
+func (t *topologyCache) AddKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
+	defer t.invalidate()
+	return t.QrouterDB.AddKeyRange(ctx, keyRange)
+}
+
+func (t *topologyCache) UpdateKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
+	defer t.invalidate()
+	return t.QrouterDB.UpdateKeyRange(ctx, keyRange)
+}
+
+func (t *topologyCache) DropKeyRange(ctx context.Context, keyRange *qdb.KeyRange) error {
+	defer t.invalidate()
+	return t.QrouterDB.DropKeyRange(ctx, keyRange)
+}
+
+func (t *topologyCache) Lock(ctx context.Context, keyRangeID string) (*qdb.KeyRange, error) {
+	defer t.invalidate()
+	return t.QrouterDB.Lock(ctx, keyRangeID)
+}
+
+func (t *topologyCache) UnLock(ctx context.Context, keyRangeID string) error {
+	defer t.invalidate()
+	return t.QrouterDB.UnLock(ctx, keyRangeID)
+}
+
+func (t *topologyCache) AddShardingRule(ctx context.Context, rule *qdb.ShardingRule) error {
+	defer t.invalidate()
+	return t.QrouterDB.AddShardingRule(ctx, rule)
+}
+
+var _ qdb.QrouterDB = &topologyCache{}
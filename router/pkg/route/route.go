@@ -34,7 +34,7 @@ func NewRoute(beRule *config.BERule, frRule *config.FRRule, mapping map[string]*
 	return &Route{
 		beRule:   beRule,
 		frRule:   frRule,
-		servPool: conn.NewConnPool(mapping),
+		servPool: conn.NewConnPool(mapping, frRule.ReadPolicy),
 		clPool:   client.NewClientPool(),
 	}
 }
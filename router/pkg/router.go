@@ -34,6 +34,9 @@ type RouterImpl struct {
 }
 
 func (r *RouterImpl) ID() string {
+	if id := config.RouterConfig().RouterID; id != "" {
+		return id
+	}
 	return "noid"
 }
 
@@ -54,6 +57,20 @@ func NewRouter(ctx context.Context) (*RouterImpl, error) {
 		return nil, err
 	}
 
+	for _, rule := range config.RouterConfig().RouterConfig.FrontendRules {
+		if rule.MultiShardTxPolicy != config.MultiShardTxTwoPhase {
+			continue
+		}
+
+		// commit decisions are recorded in shared qdb of sharding router only
+		if qtype != config.ShardQrouter {
+			return nil, xerrors.Errorf("two-phase commit of route %s %s requires %s qrouter", rule.RK.Usr, rule.RK.DB, config.ShardQrouter)
+		}
+		if err := rrouter.CheckRouterID(config.RouterConfig().RouterID); err != nil {
+			return nil, xerrors.Errorf("two-phase commit of route %s %s requires router id: %w", rule.RK.Usr, rule.RK.DB, err)
+		}
+	}
+
 	// frontend
 	frTlsCfg := config.RouterConfig().RouterConfig.TLSCfg
 	frTLS, err := config.InitTLS(frTlsCfg.SslMode, frTlsCfg.CertFile, frTlsCfg.KeyFile)
@@ -72,6 +89,13 @@ func NewRouter(ctx context.Context) (*RouterImpl, error) {
 		tracelog.InfoLogger.PrintError(err)
 	}
 
+	if qtype == config.ShardQrouter && rrouter.CheckRouterID(config.RouterConfig().RouterID) == nil {
+		// resolve distributed transactions of router, interrupted by its previous failure
+		if err := rrouter.RecoverTransactions(ctx, qr, config.RouterConfig().RouterConfig.ShardMapping); err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+		}
+	}
+
   stchan := make(chan struct{})
 	localConsole, err := console.NewConsole(frTLS, qr, rr, stchan)
 	if err != nil {
@@ -81,10 +105,15 @@ func NewRouter(ctx context.Context) (*RouterImpl, error) {
 
 	executer := NewExecuter(config.RouterConfig().ExecuterCfg)
 
-	for _, fname := range []string{
+	fnames := []string{
 		config.RouterConfig().InitSQL,
-		config.RouterConfig().AutoConf,
-	} {
+	}
+	if qtype != config.ShardQrouter {
+		// topology of sharding router is kept in qdb
+		fnames = append(fnames, config.RouterConfig().AutoConf)
+	}
+
+	for _, fname := range fnames {
 		queries, err := localConsole.Qlog.Recover(ctx, fname)
 		if err != nil {
 			tracelog.ErrorLogger.PrintError(xerrors.Errorf("failed to initialize router: %w", err))
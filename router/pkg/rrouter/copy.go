@@ -0,0 +1,349 @@
+package rrouter
+
+import (
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/router/pkg/server"
+	"github.com/wal-g/tracelog"
+	"golang.org/x/xerrors"
+)
+
+// copyShard is state of COPY FROM STDIN session on datashard
+type copyShard struct {
+	shkey kr.ShardKey
+
+	// datashard waits for COPY data
+	copying bool
+	// datashard replied with ReadyForQuery
+	done bool
+
+	// CommandComplete or ErrorResponse, which finished COPY
+	result pgproto3.BackendMessage
+	txst   byte
+}
+
+type copyEvent struct {
+	shard *copyShard
+	msg   pgproto3.BackendMessage
+	err   error
+}
+
+// copySession is COPY FROM STDIN, executed on every active datashard in parallel
+type copySession struct {
+	srv    server.Server
+	shards []*copyShard
+	events chan copyEvent
+
+	// first CopyInResponse of datashards, relayed to client
+	inResponse *pgproto3.CopyInResponse
+}
+
+// receiveCopy reads datashard replies until ReadyForQuery.
+// pgproto3 reuses message structs, so replies are copied
+func receiveCopy(srv server.Server, sh *copyShard, events chan<- copyEvent) {
+	for {
+		msg, err := srv.ReceiveShard(sh.shkey)
+		if err != nil {
+			events <- copyEvent{shard: sh, err: err}
+			return
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.CopyInResponse:
+			cp := *v
+			events <- copyEvent{shard: sh, msg: &cp}
+		case *pgproto3.CommandComplete:
+			events <- copyEvent{shard: sh, msg: &pgproto3.CommandComplete{
+				CommandTag: append([]byte(nil), v.CommandTag...),
+			}}
+		case *pgproto3.ErrorResponse:
+			cp := *v
+			events <- copyEvent{shard: sh, msg: &cp}
+		case *pgproto3.ReadyForQuery:
+			events <- copyEvent{shard: sh, msg: &pgproto3.ReadyForQuery{TxStatus: v.TxStatus}}
+			return
+		}
+	}
+}
+
+func (s *copySession) handle(ev copyEvent) error {
+	if ev.err != nil {
+		return ev.err
+	}
+
+	switch v := ev.msg.(type) {
+	case *pgproto3.CopyInResponse:
+		ev.shard.copying = true
+		if s.inResponse == nil {
+			s.inResponse = v
+		}
+	case *pgproto3.CommandComplete, *pgproto3.ErrorResponse:
+		ev.shard.copying = false
+		if ev.shard.result == nil {
+			ev.shard.result = v
+		}
+	case *pgproto3.ReadyForQuery:
+		ev.shard.copying = false
+		ev.shard.done = true
+		ev.shard.txst = v.TxStatus
+	}
+
+	return nil
+}
+
+// wait handles datashard replies until every datashard satisfies cond
+func (s *copySession) wait(cond func(sh *copyShard) bool) error {
+	for {
+		waiting := false
+		for _, sh := range s.shards {
+			if !cond(sh) {
+				waiting = true
+			}
+		}
+		if !waiting {
+			return nil
+		}
+
+		if err := s.handle(<-s.events); err != nil {
+			return err
+		}
+	}
+}
+
+// poll handles datashard replies, which are already received
+func (s *copySession) poll() error {
+	for {
+		select {
+		case ev := <-s.events:
+			if err := s.handle(ev); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// failed returns first error of datashards
+func (s *copySession) failed() *pgproto3.ErrorResponse {
+	for _, sh := range s.shards {
+		if errmsg, ok := sh.result.(*pgproto3.ErrorResponse); ok {
+			return errmsg
+		}
+	}
+
+	return nil
+}
+
+// send sends message to every datashard in COPY mode
+func (s *copySession) send(msg pgproto3.FrontendMessage) error {
+	for _, sh := range s.shards {
+		if !sh.copying {
+			continue
+		}
+		if err := s.srv.SendShard(sh.shkey, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *copySession) sendRows(rows map[kr.ShardKey][]byte) error {
+	for shkey, data := range rows {
+		if err := s.srv.SendShard(shkey, &pgproto3.CopyData{Data: data}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// abort fails COPY on every datashard and waits for their replies
+func (s *copySession) abort(reason string) error {
+	if err := s.send(&pgproto3.CopyFail{Message: reason}); err != nil {
+		return err
+	}
+
+	return s.wait(func(sh *copyShard) bool { return sh.done })
+}
+
+// txStatus merges transaction status of datashards
+func (s *copySession) txStatus() byte {
+	for _, sh := range s.shards {
+		if sh.txst == conn.TXERR {
+			return conn.TXERR
+		}
+	}
+
+	return s.shards[0].txst
+}
+
+// execShards executes utility query on every active datashard
+// and returns first error reply
+func (rst *RelayStateImpl) execShards(query string) (*pgproto3.ErrorResponse, byte, error) {
+	replies, err := rst.execEach(rst.ActiveShards, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var errmsg *pgproto3.ErrorResponse
+	var txst byte
+
+	for _, reply := range replies {
+		if errmsg == nil {
+			errmsg = reply.errmsg
+		}
+		txst = reply.txst
+	}
+
+	return errmsg, txst, nil
+}
+
+// relayCopyIn executes COPY FROM STDIN on every active datashard, routing client
+// data rows between them. Outside of transaction block COPY is wrapped in transaction,
+// so failure on any datashard aborts all of them.
+func (rst *RelayStateImpl) relayCopyIn(q *pgproto3.Query, txst byte) (byte, error) {
+	wrap := txst != conn.NOTXREL && txst != conn.TXERR
+	if wrap {
+		errmsg, txst, err := rst.execShards("BEGIN")
+		if err != nil {
+			return 0, err
+		}
+		if errmsg != nil {
+			return txst, rst.Cl.Send(errmsg)
+		}
+	}
+
+	sess := &copySession{
+		srv:    rst.Cl.Server(),
+		events: make(chan copyEvent, 3*len(rst.ActiveShards)),
+	}
+
+	for _, shkey := range rst.ActiveShards {
+		if err := sess.srv.SendShard(shkey, q); err != nil {
+			return 0, err
+		}
+
+		sh := &copyShard{shkey: shkey}
+		sess.shards = append(sess.shards, sh)
+		go receiveCopy(sess.srv, sh, sess.events)
+	}
+
+	if err := sess.wait(func(sh *copyShard) bool { return sh.copying || sh.done }); err != nil {
+		return 0, err
+	}
+
+	errmsg := sess.failed()
+	if errmsg != nil {
+		if err := sess.abort("COPY failed on another datashard"); err != nil {
+			return 0, err
+		}
+	} else {
+		if err := rst.Cl.Send(sess.inResponse); err != nil {
+			return 0, err
+		}
+
+		var err error
+		if errmsg, err = rst.relayCopyData(sess); err != nil {
+			return 0, err
+		}
+	}
+
+	txst = sess.txStatus()
+
+	if wrap {
+		var err error
+		if errmsg == nil {
+			errmsg, txst, err = rst.execShards("COMMIT")
+		} else {
+			_, txst, err = rst.execShards("ROLLBACK")
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if errmsg != nil {
+		return txst, rst.Cl.Send(errmsg)
+	}
+
+	tags := make([][]byte, 0, len(sess.shards))
+	for _, sh := range sess.shards {
+		tags = append(tags, sh.result.(*pgproto3.CommandComplete).CommandTag)
+	}
+
+	return txst, rst.Cl.Send(&pgproto3.CommandComplete{CommandTag: server.MergeCommandTags(tags)})
+}
+
+// relayCopyData routes client COPY data to datashards until client finishes COPY.
+// Returned error reply is relayed to client
+func (rst *RelayStateImpl) relayCopyData(sess *copySession) (*pgproto3.ErrorResponse, error) {
+	for {
+		msg, err := rst.Cl.Receive()
+		if err != nil {
+			return nil, err
+		}
+
+		var rows map[kr.ShardKey][]byte
+		var routeErr error
+
+		switch v := msg.(type) {
+		case *pgproto3.CopyData:
+			rows, routeErr = rst.copyRows.Route(v.Data)
+		case *pgproto3.CopyDone:
+			if rows, routeErr = rst.copyRows.Flush(); routeErr != nil {
+				break
+			}
+			if err := sess.sendRows(rows); err != nil {
+				return nil, err
+			}
+			if err := sess.send(&pgproto3.CopyDone{}); err != nil {
+				return nil, err
+			}
+			if err := sess.wait(func(sh *copyShard) bool { return sh.done }); err != nil {
+				return nil, err
+			}
+
+			return sess.failed(), nil
+		case *pgproto3.CopyFail:
+			if err := sess.abort(v.Message); err != nil {
+				return nil, err
+			}
+
+			return sess.failed(), nil
+		case *pgproto3.Flush, *pgproto3.Sync:
+			continue
+		default:
+			routeErr = xerrors.Errorf("unexpected message type %T during COPY", msg)
+		}
+
+		if routeErr != nil {
+			tracelog.InfoLogger.Printf("abort COPY: %v", routeErr)
+			if err := sess.abort(routeErr.Error()); err != nil {
+				return nil, err
+			}
+
+			return &pgproto3.ErrorResponse{
+				Severity: "ERROR",
+				Message:  routeErr.Error(),
+			}, nil
+		}
+
+		if err := sess.sendRows(rows); err != nil {
+			return nil, err
+		}
+
+		if err := sess.poll(); err != nil {
+			return nil, err
+		}
+		if sess.failed() != nil {
+			if err := sess.abort("COPY failed on another datashard"); err != nil {
+				return nil, err
+			}
+
+			return sess.failed(), nil
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package rrouter
+
+import (
+	"github.com/jackc/pgproto3/v2"
+)
+
+// SetHint sets routing hint of client session, intercepted from
+// SET spqr.<hint> statement. Empty value resets hint
+func (rst *RelayStateImpl) SetHint(name, value string) error {
+	if err := rst.hints.Set(name, value); err != nil {
+		return rst.Cl.ReplyErr(err.Error())
+	}
+
+	tag := "SET"
+	if value == "" {
+		tag = "RESET"
+	}
+
+	for _, msg := range []pgproto3.BackendMessage{
+		&pgproto3.CommandComplete{CommandTag: []byte(tag)},
+		&pgproto3.ReadyForQuery{TxStatus: rst.txStatus},
+	} {
+		if err := rst.Cl.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
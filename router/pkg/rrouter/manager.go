@@ -9,8 +9,15 @@ import (
 	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/router/pkg/client"
 	"github.com/pkg/errors"
+	"golang.org/x/xerrors"
 )
 
+// MultiShardTxError is returned, when route policy rejects transaction, spanning several datashards
+var MultiShardTxError = xerrors.New("transaction spanning several datashards is rejected by route policy")
+
+// MultiShardTxErrorCode is SQLSTATE of MultiShardTxError: feature_not_supported
+const MultiShardTxErrorCode = "0A000"
+
 type ConnManager interface {
 	TXBeginCB(client client.RouterClient, rst *RelayStateImpl) error
 	TXEndCB(client client.RouterClient, rst *RelayStateImpl) error
@@ -28,6 +35,22 @@ func unRouteWithError(cmngr ConnManager, client client.RouterClient, sh []kr.Sha
 	return client.ReplyErr(errmsg.Error())
 }
 
+// checkMultiShardTx enforces multi-shard transaction policy of client route
+func checkMultiShardTx(client client.RouterClient, sh []kr.ShardKey) error {
+	if len(sh) < 2 {
+		return nil
+	}
+
+	switch policy := client.Rule().MultiShardTxPolicy; policy {
+	case config.MultiShardTxReject:
+		return MultiShardTxError
+	case config.MultiShardTxAllow, config.MultiShardTxTwoPhase, "":
+		return nil
+	default:
+		return xerrors.Errorf("unknown multi-shard transaction policy %v", policy)
+	}
+}
+
 type TxConnManager struct{}
 
 func (t *TxConnManager) UnRouteWithError(client client.RouterClient, sh []kr.ShardKey, errmsg error) error {
@@ -49,6 +72,9 @@ func NewTxConnManager() *TxConnManager {
 }
 
 func (t *TxConnManager) RouteCB(client client.RouterClient, sh []kr.ShardKey) error {
+	if err := checkMultiShardTx(client, sh); err != nil {
+		return err
+	}
 
 	for _, shkey := range sh {
 		asynctracelog.Printf("adding datashard %v", shkey.Name)
@@ -96,7 +122,7 @@ func (s *SessConnManager) UnRouteCB(cl client.RouterClient, sh []kr.ShardKey) er
 		}
 	}
 
-	return nil
+	return cl.Unroute()
 }
 
 func (s *SessConnManager) TXBeginCB(client client.RouterClient, rst *RelayStateImpl) error {
@@ -108,6 +134,10 @@ func (s *SessConnManager) TXEndCB(client client.RouterClient, rst *RelayStateImp
 }
 
 func (s *SessConnManager) RouteCB(client client.RouterClient, sh []kr.ShardKey) error {
+	if err := checkMultiShardTx(client, sh); err != nil {
+		return err
+	}
+
 	for _, shkey := range sh {
 		if err := client.Server().AddShard(shkey); err != nil {
 			return err
@@ -117,8 +147,9 @@ func (s *SessConnManager) RouteCB(client client.RouterClient, sh []kr.ShardKey)
 	return nil
 }
 
+// ValidateReRoute allows to reroute session once, or from replica to primary
 func (s *SessConnManager) ValidateReRoute(rst *RelayStateImpl) bool {
-	return rst.ActiveShards == nil
+	return rst.ActiveShards == nil || rst.writeOnReplica()
 }
 
 func NewSessConnManager() *SessConnManager {
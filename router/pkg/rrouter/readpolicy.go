@@ -0,0 +1,120 @@
+package rrouter
+
+import (
+	"strings"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/router/pkg/qrouter"
+	"golang.org/x/xerrors"
+)
+
+// beginStmt checks if query starts transaction block,
+// and if the transaction block is read-only
+func beginStmt(q string) (bool, bool) {
+	q = strings.ToLower(strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(q), ";")), " "))
+
+	if q != "begin" && !strings.HasPrefix(q, "begin ") && !strings.HasPrefix(q, "start transaction") {
+		return false, false
+	}
+
+	return true, strings.Contains(q, "read only")
+}
+
+// bufferedQueries returns queries, which are not executed yet:
+// deferred queries and queries of current extended protocol batch
+func (rst *RelayStateImpl) bufferedQueries() []string {
+	queries := make([]string, 0, len(rst.msgBuf))
+	for _, q := range rst.msgBuf {
+		queries = append(queries, q.String)
+	}
+
+	for _, batch := range append(rst.xPending, rst.xBuf) {
+		for _, msg := range batch {
+			switch v := msg.(type) {
+			case *pgproto3.Parse:
+				queries = append(queries, v.Query)
+			case *pgproto3.Bind:
+				if parse, ok := rst.prepStmts[v.PreparedStatement]; ok {
+					queries = append(queries, parse.Query)
+				}
+			}
+		}
+	}
+
+	return queries
+}
+
+// explicitTx checks if routed query is executed in transaction block,
+// started by deferred query, and if the transaction block is read-only
+func (rst *RelayStateImpl) explicitTx() (bool, bool) {
+	for _, q := range rst.bufferedQueries() {
+		if begin, readOnly := beginStmt(q); begin {
+			return true, readOnly
+		}
+	}
+
+	return false, false
+}
+
+// ReadsOnPrimary checks if read policy executes read-only queries on primary
+func ReadsOnPrimary(policy config.ReadPolicy) (bool, error) {
+	switch policy {
+	case config.ReadPolicyPrimaryOnly, "":
+		return true, nil
+	case config.ReadPolicyPreferReplica, config.ReadPolicyReplicaOnly:
+		return false, nil
+	default:
+		return false, xerrors.Errorf("unknown read policy %v", policy)
+	}
+}
+
+// applyReadPolicy routes read-only queries to primary or to replicas by read policy
+// of client route. Transaction block, which is not read-only, is executed on primary,
+// as it may modify data later
+func (rst *RelayStateImpl) applyReadPolicy(routes []*qrouter.ShardRoute) error {
+	primary, err := ReadsOnPrimary(rst.Cl.Rule().ReadPolicy)
+	if err != nil {
+		return err
+	}
+
+	if begin, readOnly := rst.explicitTx(); begin && !readOnly {
+		primary = true
+	}
+
+	if primary {
+		for _, route := range routes {
+			route.Shkey.RW = true
+		}
+	}
+
+	return nil
+}
+
+// writeOnReplica checks if idle session, routed to replica, executes
+// buffered query, which may modify data, so session is rerouted to primary
+func (rst *RelayStateImpl) writeOnReplica() bool {
+	if rst.txStatus != conn.TXREL {
+		return false
+	}
+
+	replica := false
+	for _, shkey := range rst.ActiveShards {
+		replica = replica || !shkey.RW
+	}
+	if !replica {
+		return false
+	}
+
+	for _, q := range rst.bufferedQueries() {
+		if begin, readOnly := beginStmt(q); begin && readOnly {
+			continue
+		}
+		if !qrouter.ReadOnly(q) {
+			return true
+		}
+	}
+
+	return false
+}
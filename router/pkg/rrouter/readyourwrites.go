@@ -0,0 +1,175 @@
+package rrouter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/router/pkg/qrouter"
+	"github.com/wal-g/tracelog"
+	"golang.org/x/xerrors"
+)
+
+// replayPollInterval is period of checks, if replica replayed writes of client session
+const replayPollInterval = time.Millisecond * 10
+
+// ReplayTimeoutError is returned, when replica does not replay writes of client session in time
+// and read policy does not allow to execute read-only query on primary
+var ReplayTimeoutError = xerrors.New("replica did not replay writes of client session in time")
+
+// replicaReads checks if read policy allows to execute read-only queries on replicas
+func replicaReads(rule *config.FRRule) bool {
+	switch rule.ReadPolicy {
+	case config.ReadPolicyPreferReplica, config.ReadPolicyReplicaOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+// primaryShards returns active datashards, which client session is routed to primary of
+func (rst *RelayStateImpl) primaryShards() []kr.ShardKey {
+	var ret []kr.ShardKey
+	for _, shkey := range rst.ActiveShards {
+		if shkey.RW {
+			ret = append(ret, shkey)
+		}
+	}
+
+	return ret
+}
+
+// trackWrites remembers, if buffered queries may modify data on primary.
+// Writes are tracked only if read-only queries may be executed on replicas
+func (rst *RelayStateImpl) trackWrites() {
+	if rst.wrote || !replicaReads(rst.Cl.Rule()) || len(rst.primaryShards()) == 0 {
+		return
+	}
+
+	for _, q := range rst.bufferedQueries() {
+		if begin, _ := beginStmt(q); begin || isCommitStmt(q) {
+			continue
+		}
+		if !qrouter.ReadOnly(q) {
+			rst.wrote = true
+			return
+		}
+	}
+}
+
+// recordWrites remembers WAL positions of primaries after write transaction of client session,
+// so next read-only queries of the session are executed on replicas, which replayed it
+func (rst *RelayStateImpl) recordWrites() error {
+	if !rst.wrote {
+		return nil
+	}
+	rst.wrote = false
+
+	shkeys := rst.primaryShards()
+	if len(shkeys) == 0 {
+		return nil
+	}
+
+	replies, err := rst.execEach(shkeys, "SELECT pg_current_wal_lsn()")
+	if err != nil {
+		return err
+	}
+
+	for _, reply := range replies {
+		if reply.errmsg != nil || len(reply.row) == 0 || reply.row[0] == nil {
+			tracelog.ErrorLogger.Printf("failed to get WAL position of datashard %v primary: %v", reply.shkey.Name, reply.errmsg)
+			continue
+		}
+
+		rst.writeLSNs[reply.shkey.Name] = string(reply.row[0])
+	}
+
+	return nil
+}
+
+// notReplayed returns datashards, which replica of has not replayed writes of client session yet.
+// Replayed writes are forgotten, so later reads of the session are not checked
+func (rst *RelayStateImpl) notReplayed(shkeys []kr.ShardKey) ([]kr.ShardKey, error) {
+	var ret []kr.ShardKey
+
+	for _, shkey := range shkeys {
+		// primary is used for read-only queries, if no replica is available
+		query := fmt.Sprintf("SELECT NOT pg_is_in_recovery() OR pg_last_wal_replay_lsn() >= '%s'", rst.writeLSNs[shkey.Name])
+
+		replies, err := rst.execEach([]kr.ShardKey{shkey}, query)
+		if err != nil {
+			return nil, err
+		}
+
+		if reply := replies[0]; reply.errmsg != nil || len(reply.row) == 0 || string(reply.row[0]) != "t" {
+			if reply.errmsg != nil {
+				tracelog.ErrorLogger.Printf("failed to check replay position of datashard %v replica: %v", shkey.Name, reply.errmsg.Message)
+			}
+			ret = append(ret, shkey)
+			continue
+		}
+
+		delete(rst.writeLSNs, shkey.Name)
+	}
+
+	return ret, nil
+}
+
+// awaitWrites waits, until replicas, which client session is routed to, replay last writes
+// of the session. Routes are switched to primary, if replica does not catch up in time
+func (rst *RelayStateImpl) awaitWrites(routingState qrouter.RoutingState) error {
+	var routes []*qrouter.ShardRoute
+
+	switch v := routingState.(type) {
+	case qrouter.ShardMatchState:
+		routes = v.Routes
+	case qrouter.CopyRouteState:
+		routes = v.Routes
+	default:
+		return nil
+	}
+
+	var pending []kr.ShardKey
+	for _, route := range routes {
+		if _, ok := rst.writeLSNs[route.Shkey.Name]; ok && !route.Shkey.RW {
+			pending = append(pending, route.Shkey)
+		}
+	}
+
+	deadline := time.Now().Add(config.RouterConfig().RouterConfig.ReadYourWritesTimeout)
+
+	for len(pending) > 0 {
+		var err error
+		if pending, err = rst.notReplayed(pending); err != nil {
+			return err
+		}
+
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(replayPollInterval)
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if rst.Cl.Rule().ReadPolicy == config.ReadPolicyReplicaOnly {
+		_ = rst.Reset()
+		return ReplayTimeoutError
+	}
+
+	tracelog.InfoLogger.Printf("replicas of datashards %v did not replay writes of client session in time, route to primary", pending)
+
+	for _, route := range routes {
+		for _, shkey := range pending {
+			if route.Shkey.Name == shkey.Name {
+				route.Shkey.RW = true
+			}
+		}
+	}
+
+	return rst.routeTo(routingState)
+}
@@ -21,10 +21,18 @@ type RelayStateInteractor interface {
 	Flush()
 	Reroute(q *pgproto3.Query) error
 	ShouldRetry(err error) bool
+
+	AddExtendedProtocMessage(q pgproto3.FrontendMessage)
+	RerouteExtended() error
+	DeferExtended() error
+	FlushExtended()
+	RelayExtendedStep() (byte, error)
 }
 
 type RelayStateImpl struct {
 	TxActive bool
+	// transaction status of client session, relayed to client last time
+	txStatus byte
 
 	ActiveShards []kr.ShardKey
 
@@ -37,13 +45,36 @@ type RelayStateImpl struct {
 	manager ConnManager
 
 	msgBuf []pgproto3.Query
+	// last buffered query, rewritten for every active shard
+	shardQueries map[kr.ShardKey]*pgproto3.Query
+	// rows of last buffered query, if it is COPY FROM STDIN
+	copyRows *qrouter.CopyRowRouter
+	// merge plan of last buffered query rows, if it is scattered SELECT
+	mergePlan *server.MergePlan
+
+	// extended protocol messages received since last Sync
+	xBuf []pgproto3.FrontendMessage
+	// extended protocol batches acknowledged to client, but not executed yet
+	xPending [][]pgproto3.FrontendMessage
+	// prepared statements of client session, re-created on every server connection
+	prepStmts map[string]*pgproto3.Parse
+	// routing hints of client session
+	hints qrouter.Hints
+
+	// current transaction may modify data on primary
+	wrote bool
+	// WAL positions of primaries after last write transaction of client session, by datashard
+	writeLSNs map[string]string
 }
 
 func NewRelayState(qr qrouter.QueryRouter, client client.RouterClient, manager ConnManager) *RelayStateImpl {
 	return &RelayStateImpl{
 		ActiveShards: nil,
 		TxActive:     false,
+		txStatus:     conn.TXREL,
 		msgBuf:       nil,
+		prepStmts:    map[string]*pgproto3.Parse{},
+		writeLSNs:    map[string]string{},
 		traceMsgs:    false,
 		Qr:           qr,
 		Cl:           client,
@@ -53,7 +84,11 @@ func NewRelayState(qr qrouter.QueryRouter, client client.RouterClient, manager C
 
 func (rst *RelayStateImpl) Reset() error {
 	rst.ActiveShards = nil
+	rst.shardQueries = nil
+	rst.copyRows = nil
+	rst.mergePlan = nil
 	rst.TxActive = false
+	rst.txStatus = conn.TXREL
 
 	_ = rst.Cl.Reset()
 
@@ -72,6 +107,10 @@ func (rst *RelayStateImpl) Flush() {
 var SkipQueryError = xerrors.New("wait for next query")
 
 func (rst *RelayStateImpl) Reroute(q *pgproto3.Query) error {
+	return rst.reroute(q.String, nil)
+}
+
+func (rst *RelayStateImpl) reroute(q string, bind *qrouter.BindParams) error {
 
 	tracelog.InfoLogger.Printf("rerouting")
 	_ = rst.Cl.ReplyNotice(fmt.Sprintf("rerouting your connection"))
@@ -80,14 +119,43 @@ func (rst *RelayStateImpl) Reroute(q *pgproto3.Query) error {
 	defer span.Finish()
 	span.SetTag("user", rst.Cl.Usr())
 	span.SetTag("db", rst.Cl.DB())
-	span.SetTag("query", q.String)
+	span.SetTag("query", q)
 
-	routingState, err := rst.Qr.Route(q.String)
+	routingState, err := rst.Qr.RouteWithHints(q, bind, rst.hints)
 	rst.Cl.ReplyNotice(fmt.Sprintf("rerouting state %T %v", routingState, err))
 	if err != nil {
 		return err
 	}
 
+	return rst.rerouteTo(routingState)
+}
+
+// rerouteTo connects client session to datashards of routing state,
+// which are chosen by read policy
+func (rst *RelayStateImpl) rerouteTo(routingState qrouter.RoutingState) error {
+	rst.copyRows = nil
+	rst.mergePlan = nil
+
+	var err error
+	switch v := routingState.(type) {
+	case qrouter.ShardMatchState:
+		err = rst.applyReadPolicy(v.Routes)
+	case qrouter.CopyRouteState:
+		err = rst.applyReadPolicy(v.Routes)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := rst.routeTo(routingState); err != nil {
+		return err
+	}
+
+	return rst.awaitWrites(routingState)
+}
+
+// routeTo connects client session to datashards of routing state
+func (rst *RelayStateImpl) routeTo(routingState qrouter.RoutingState) error {
 	switch v := routingState.(type) {
 	case qrouter.ShardMatchState:
 
@@ -98,15 +166,24 @@ func (rst *RelayStateImpl) Reroute(q *pgproto3.Query) error {
 			return qrouter.MatchShardError
 		}
 
-		if err := rst.manager.UnRouteCB(rst.Cl, rst.ActiveShards); err != client.NotRouted {
+		if err := rst.manager.UnRouteCB(rst.Cl, rst.ActiveShards); err != nil && err != client.NotRouted {
 			tracelog.ErrorLogger.PrintError(err)
 			return err
 		}
 
 		rst.ActiveShards = nil
+		rst.shardQueries = nil
 		for _, shr := range v.Routes {
 			rst.ActiveShards = append(rst.ActiveShards, shr.Shkey)
+
+			if shr.Query != "" {
+				if rst.shardQueries == nil {
+					rst.shardQueries = map[kr.ShardKey]*pgproto3.Query{}
+				}
+				rst.shardQueries[shr.Shkey] = &pgproto3.Query{String: shr.Query}
+			}
 		}
+		rst.mergePlan = v.Merge
 		//
 		if err := rst.Cl.ReplyNotice(fmt.Sprintf("matched datashard routes %v", v.Routes)); err != nil {
 			return err
@@ -115,18 +192,45 @@ func (rst *RelayStateImpl) Reroute(q *pgproto3.Query) error {
 		if err := rst.Connect(v.Routes); err != nil {
 			tracelog.InfoLogger.Printf("encounter %w while initialing server connection", err)
 			_ = rst.Reset()
-			_ = rst.Cl.ReplyErr(err.Error())
+			if err != MultiShardTxError {
+				_ = rst.Cl.ReplyErr(err.Error())
+			}
 			return err
 		}
 
 		return nil
 
+	case qrouter.CopyRouteState:
+
+		if err := rst.manager.UnRouteCB(rst.Cl, rst.ActiveShards); err != nil && err != client.NotRouted {
+			tracelog.ErrorLogger.PrintError(err)
+			return err
+		}
+
+		rst.ActiveShards = nil
+		rst.shardQueries = nil
+		for _, shr := range v.Routes {
+			rst.ActiveShards = append(rst.ActiveShards, shr.Shkey)
+		}
+		rst.copyRows = v.Rows
+
+		if err := rst.Connect(v.Routes); err != nil {
+			tracelog.InfoLogger.Printf("encounter %v while initialing server connection", err)
+			_ = rst.Reset()
+			if err != MultiShardTxError {
+				_ = rst.Cl.ReplyErr(err.Error())
+			}
+			return err
+		}
+
+		return nil
 	case qrouter.SkipRoutingState:
 		return SkipQueryError
 	case qrouter.WolrdRouteState:
 
 		if !config.RouterConfig().RouterConfig.WorldShardFallback {
-			return err
+			// query to relations without sharding rules is rejected
+			return qrouter.MatchShardError
 		}
 		// fallback to execute query on wolrd datashard (s)
 
@@ -231,17 +335,42 @@ func (rst *RelayStateImpl) RelayStep() (byte, error) {
 		rst.TxActive = true
 	}
 
+	rst.trackWrites()
+
 	var txst byte
 	var err error
 
 	for len(rst.msgBuf) > 0 {
 		var v *pgproto3.Query
 		v, rst.msgBuf = &rst.msgBuf[0], rst.msgBuf[1:]
-		if txst, err = rst.Cl.ProcQuery(v); err != nil {
+
+		if len(rst.msgBuf) == 0 && rst.mergePlan != nil {
+			rst.Cl.Server().SetMergePlan(rst.mergePlan)
+		}
+
+		switch {
+		case len(rst.ActiveShards) > 1 && rst.txStatus == conn.NOTXREL && isCommitStmt(v.String) && twoPhaseCommit(rst.Cl.Rule()):
+			txst, err = rst.relayCommit()
+		case len(rst.msgBuf) == 0 && rst.copyRows != nil:
+			txst, err = rst.relayCopyIn(v, rst.txStatus)
+		case len(rst.msgBuf) == 0 && rst.shardQueries != nil:
+			txst, err = rst.Cl.ProcShardQueries(rst.shardQueries)
+		default:
+			txst, err = rst.Cl.ProcQuery(v)
+		}
+		if err != nil {
+			rst.shardQueries = nil
+			rst.copyRows = nil
+			rst.mergePlan = nil
 			return 0, err
 		}
+
+		rst.txStatus = txst
 	}
 
+	rst.shardQueries = nil
+	rst.copyRows = nil
+	rst.mergePlan = nil
 	return txst, nil
 }
 
@@ -253,12 +382,20 @@ func (rst *RelayStateImpl) CompleteRelay(txst byte) error {
 
 	tracelog.InfoLogger.Printf("complete relay iter with TX status %v", txst)
 
-	if err := rst.Cl.Send(&pgproto3.ReadyForQuery{}); err != nil {
+	rst.txStatus = txst
+
+	if err := rst.Cl.Send(&pgproto3.ReadyForQuery{
+		TxStatus: txst,
+	}); err != nil {
 		return err
 	}
 
 	switch txst {
 	case conn.TXREL:
+		if err := rst.recordWrites(); err != nil {
+			return err
+		}
+
 		if rst.TxActive {
 			if err := rst.manager.TXEndCB(rst.Cl, rst); err != nil {
 				return err
@@ -267,7 +404,7 @@ func (rst *RelayStateImpl) CompleteRelay(txst byte) error {
 		}
 
 		return nil
-	case conn.NOTXREL:
+	case conn.NOTXREL, conn.TXERR:
 
 		if !rst.TxActive {
 			if err := rst.manager.TXBeginCB(rst.Cl, rst); err != nil {
@@ -296,4 +433,382 @@ func (rst *RelayStateImpl) AddQuery(q pgproto3.Query) {
 	rst.msgBuf = append(rst.msgBuf, q)
 }
 
+// DiscardLastQuery drops query, rejected by router, so it is never replayed
+func (rst *RelayStateImpl) DiscardLastQuery() {
+	if len(rst.msgBuf) > 0 {
+		rst.msgBuf = rst.msgBuf[:len(rst.msgBuf)-1]
+	}
+}
+
+func copyFrontendMessage(msg pgproto3.FrontendMessage) pgproto3.FrontendMessage {
+	// pgproto3 reuses message structs between Receive calls
+	switch v := msg.(type) {
+	case *pgproto3.Parse:
+		cp := *v
+		return &cp
+	case *pgproto3.Bind:
+		cp := *v
+		return &cp
+	case *pgproto3.Describe:
+		cp := *v
+		return &cp
+	case *pgproto3.Execute:
+		cp := *v
+		return &cp
+	case *pgproto3.Close:
+		cp := *v
+		return &cp
+	default:
+		return msg
+	}
+}
+
+func (rst *RelayStateImpl) AddExtendedProtocMessage(q pgproto3.FrontendMessage) {
+	rst.xBuf = append(rst.xBuf, copyFrontendMessage(q))
+}
+
+// FlushExtended drops current batch, e.g. when it failed to route
+func (rst *RelayStateImpl) FlushExtended() {
+	rst.xBuf = nil
+}
+
+// batchStatement looks up statement, that is parsed
+// either in current batch or earlier in the session
+func (rst *RelayStateImpl) batchStatement(name string) (*pgproto3.Parse, bool) {
+	for i := len(rst.xBuf) - 1; i >= 0; i-- {
+		if v, ok := rst.xBuf[i].(*pgproto3.Parse); ok && v.Name == name {
+			return v, true
+		}
+	}
+
+	parse, ok := rst.prepStmts[name]
+	return parse, ok
+}
+
+var MultiShardBatchError = xerrors.New("statements of extended protocol batch are routed to different datashards")
+
+// batchRoutingState combines routing states of Binds of the same batch. Batch is executed
+// on single connection, so every its Bind must be routed to the same datashards.
+// Datashard is accessed on primary, if any Bind writes on it
+func batchRoutingState(prev, next qrouter.RoutingState) (qrouter.RoutingState, error) {
+	if prev == nil {
+		return next, nil
+	}
+
+	if _, ok := prev.(qrouter.WolrdRouteState); ok {
+		if _, ok := next.(qrouter.WolrdRouteState); ok {
+			return prev, nil
+		}
+	}
+
+	prevMatch, ok := prev.(qrouter.ShardMatchState)
+	if !ok {
+		return nil, MultiShardBatchError
+	}
+	nextMatch, ok := next.(qrouter.ShardMatchState)
+	if !ok || len(prevMatch.Routes) != len(nextMatch.Routes) {
+		return nil, MultiShardBatchError
+	}
+
+	rw := map[string]bool{}
+	for _, route := range nextMatch.Routes {
+		rw[route.Shkey.Name] = route.Shkey.RW
+	}
+
+	routes := make([]*qrouter.ShardRoute, 0, len(prevMatch.Routes))
+	for _, route := range prevMatch.Routes {
+		nextRW, ok := rw[route.Shkey.Name]
+		if !ok {
+			return nil, MultiShardBatchError
+		}
+
+		batchRoute := *route
+		batchRoute.Shkey.RW = route.Shkey.RW || nextRW
+		routes = append(routes, &batchRoute)
+	}
+
+	merge := prevMatch.Merge
+	if merge == nil {
+		merge = nextMatch.Merge
+	}
+
+	return qrouter.ShardMatchState{
+		Routes: routes,
+		Merge:  merge,
+	}, nil
+}
+
+// RerouteExtended routes current extended protocol batch by every its routable Bind.
+// MultiShardBatchError is returned, if Binds are routed to different datashards.
+// Batches without Bind (e.g. Parse-Describe-Sync) are routed by
+// query text or to any data shard as all of them share the same schema.
+func (rst *RelayStateImpl) RerouteExtended() error {
+	var lastErr error = SkipQueryError
+	var batchState qrouter.RoutingState
+
+	for _, msg := range rst.xBuf {
+		bind, ok := msg.(*pgproto3.Bind)
+		if !ok {
+			continue
+		}
+
+		parse, ok := rst.batchStatement(bind.PreparedStatement)
+		if !ok {
+			// let the server report missing statement
+			continue
+		}
+
+		routingState, err := rst.Qr.RouteWithHints(parse.Query, &qrouter.BindParams{
+			Values:  bind.Parameters,
+			Formats: bind.ParameterFormatCodes,
+			Types:   parse.ParameterOIDs,
+		}, rst.hints)
+		_ = rst.Cl.ReplyNotice(fmt.Sprintf("rerouting state %T %v", routingState, err))
+
+		switch err {
+		case nil:
+		case qrouter.ParseError:
+			lastErr = err
+			continue
+		default:
+			return err
+		}
+
+		if _, ok := routingState.(qrouter.SkipRoutingState); ok {
+			continue
+		}
+
+		if batchState, err = batchRoutingState(batchState, routingState); err != nil {
+			return err
+		}
+	}
+
+	if batchState != nil {
+		return rst.rerouteTo(batchState)
+	}
+
+	for _, msg := range rst.xBuf {
+		switch msg.(type) {
+		case *pgproto3.Bind, *pgproto3.Execute:
+			return lastErr
+		}
+	}
+
+	for _, msg := range rst.xBuf {
+		parse, ok := msg.(*pgproto3.Parse)
+		if !ok {
+			continue
+		}
+
+		switch err := rst.reroute(parse.Query, &qrouter.BindParams{Types: parse.ParameterOIDs}); err {
+		case SkipQueryError, qrouter.ParseError, qrouter.NoShardingKeyError:
+			// statement is only prepared, it is routed on execution
+			continue
+		default:
+			return err
+		}
+	}
+
+	shardRoutes := rst.Qr.DataShardsRoutes()
+	if len(shardRoutes) == 0 {
+		return qrouter.MatchShardError
+	}
+
+	if err := rst.manager.UnRouteCB(rst.Cl, rst.ActiveShards); err != nil && err != client.NotRouted {
+		tracelog.ErrorLogger.PrintError(err)
+		return err
+	}
+
+	rst.ActiveShards = []kr.ShardKey{shardRoutes[0].Shkey}
+
+	if err := rst.Connect(shardRoutes[:1]); err != nil {
+		_ = rst.Reset()
+		return err
+	}
+
+	return nil
+}
+
+// DeferExtended acknowledges Parse, Bind and Close messages of current batch without execution,
+// like we do for unroutable simple queries. The batch is replayed before next routed one.
+// Statement, which is described or executed, cannot be deferred: error is replied for it
+// and the rest of the batch is discarded, as server does on error
+func (rst *RelayStateImpl) DeferExtended() error {
+	batch := rst.xBuf
+
+	for i, msg := range batch {
+		var reply pgproto3.BackendMessage
+
+		switch msg.(type) {
+		case *pgproto3.Parse:
+			reply = &pgproto3.ParseComplete{}
+		case *pgproto3.Bind:
+			reply = &pgproto3.BindComplete{}
+		case *pgproto3.Close:
+			reply = &pgproto3.CloseComplete{}
+		case *pgproto3.Describe, *pgproto3.Execute:
+			rst.xBuf = batch[:i]
+			reply = &pgproto3.ErrorResponse{
+				Severity: "ERROR",
+				Message:  "failed to route statement to any datashard, statement is not executed",
+			}
+		default:
+			continue
+		}
+
+		if err := rst.Cl.Send(reply); err != nil {
+			return err
+		}
+
+		if _, ok := reply.(*pgproto3.ErrorResponse); ok {
+			break
+		}
+	}
+
+	rst.registerStatements()
+	rst.xPending = append(rst.xPending, rst.xBuf)
+	rst.xBuf = nil
+
+	return rst.Cl.Send(&pgproto3.ReadyForQuery{
+		TxStatus: conn.TXREL,
+	})
+}
+
+func (rst *RelayStateImpl) registerStatements() {
+	for _, msg := range rst.xBuf {
+		switch v := msg.(type) {
+		case *pgproto3.Parse:
+			rst.prepStmts[v.Name] = v
+		case *pgproto3.Close:
+			if v.ObjectType == 'S' {
+				delete(rst.prepStmts, v.Name)
+			}
+		}
+	}
+}
+
+// prepareStatements re-creates statements, that are used in current batch
+// but were parsed before, because server connection may differ from the one
+// they were parsed on
+func (rst *RelayStateImpl) prepareStatements() []pgproto3.FrontendMessage {
+	var msgs []pgproto3.FrontendMessage
+
+	parsed := map[string]struct{}{}
+	prepare := func(name string) {
+		if _, ok := parsed[name]; ok {
+			return
+		}
+		parsed[name] = struct{}{}
+
+		if parse, ok := rst.prepStmts[name]; ok {
+			msgs = append(msgs, &pgproto3.Close{ObjectType: 'S', Name: name}, parse)
+		}
+	}
+
+	for _, msg := range rst.xBuf {
+		switch v := msg.(type) {
+		case *pgproto3.Parse:
+			parsed[v.Name] = struct{}{}
+		case *pgproto3.Bind:
+			prepare(v.PreparedStatement)
+		case *pgproto3.Describe:
+			if v.ObjectType == 'S' {
+				prepare(v.Name)
+			}
+		}
+	}
+
+	return msgs
+}
+
+// describeResult requests row description of first executed portal of batch,
+// unless client described it, because rows are merged by their description
+func describeResult(batch []pgproto3.FrontendMessage, plan *server.MergePlan) []pgproto3.FrontendMessage {
+	portals := map[string]string{}
+	describedPortals := map[string]struct{}{}
+	describedStmts := map[string]struct{}{}
+
+	for i, msg := range batch {
+		switch v := msg.(type) {
+		case *pgproto3.Bind:
+			portals[v.DestinationPortal] = v.PreparedStatement
+			delete(describedPortals, v.DestinationPortal)
+		case *pgproto3.Describe:
+			if v.ObjectType == 'P' {
+				describedPortals[v.Name] = struct{}{}
+			} else {
+				describedStmts[v.Name] = struct{}{}
+			}
+		case *pgproto3.Execute:
+			if _, ok := describedPortals[v.Portal]; ok {
+				return batch
+			}
+			if stmt, ok := portals[v.Portal]; ok {
+				if _, ok := describedStmts[stmt]; ok {
+					return batch
+				}
+			}
+
+			plan.ImplicitDescribe = true
+
+			described := make([]pgproto3.FrontendMessage, 0, len(batch)+1)
+			described = append(described, batch[:i]...)
+			described = append(described, &pgproto3.Describe{ObjectType: 'P', Name: v.Portal})
+			return append(described, batch[i:]...)
+		}
+	}
+
+	return batch
+}
+
+func (rst *RelayStateImpl) RelayExtendedStep() (byte, error) {
+
+	if !rst.TxActive {
+		if err := rst.manager.TXBeginCB(rst.Cl, rst); err != nil {
+			return 0, err
+		}
+		rst.TxActive = true
+	}
+
+	rst.trackWrites()
+
+	// client already got replies for deferred queries, so do not proxy them twice
+	for len(rst.msgBuf) > 0 {
+		var v *pgproto3.Query
+		v, rst.msgBuf = &rst.msgBuf[0], rst.msgBuf[1:]
+		if _, err := rst.Cl.ProcMessages([]pgproto3.FrontendMessage{v}, false); err != nil {
+			return 0, err
+		}
+	}
+
+	for len(rst.xPending) > 0 {
+		var batch []pgproto3.FrontendMessage
+		batch, rst.xPending = rst.xPending[0], rst.xPending[1:]
+		if _, err := rst.Cl.ProcMessages(append(batch, &pgproto3.Sync{}), false); err != nil {
+			return 0, err
+		}
+	}
+
+	if prep := rst.prepareStatements(); len(prep) > 0 {
+		if _, err := rst.Cl.ProcMessages(append(prep, &pgproto3.Sync{}), false); err != nil {
+			return 0, err
+		}
+	}
+
+	rst.registerStatements()
+
+	batch := append(rst.xBuf, &pgproto3.Sync{})
+	rst.xBuf = nil
+
+	// rewritten queries are not relayed via extended protocol,
+	// so rows of original query are not merged
+	if rst.mergePlan != nil && rst.shardQueries == nil {
+		batch = describeResult(batch, rst.mergePlan)
+		rst.Cl.Server().SetMergePlan(rst.mergePlan)
+	}
+	rst.mergePlan = nil
+
+	return rst.Cl.ProcMessages(batch, true)
+}
+
 var _ RelayStateInteractor = &RelayStateImpl{}
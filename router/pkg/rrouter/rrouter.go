@@ -5,11 +5,13 @@ import (
 	"log"
 	"net"
 	"os"
+	"sort"
 	"sync"
 
 	"github.com/jackc/pgproto3/v2"
 	"github.com/pg-sharding/spqr/pkg/client"
 	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/conn"
 	"github.com/pg-sharding/spqr/qdb"
 	rclient "github.com/pg-sharding/spqr/router/pkg/client"
 	"github.com/pg-sharding/spqr/router/pkg/route"
@@ -26,6 +28,8 @@ type RequestRouter interface {
 	AddDataShard(key qdb.ShardKey) error
 	AddWorldShard(key qdb.ShardKey) error
 	AddShardInstance(key qdb.ShardKey, cfg *config.InstanceCFG)
+
+	ListHosts() []*conn.HostStatus
 }
 
 type RRouter struct {
@@ -48,26 +52,50 @@ func (r *RRouter) AddWorldShard(key qdb.ShardKey) error {
 }
 
 func (r *RRouter) AddShardInstance(key qdb.ShardKey, cfg *config.InstanceCFG) {
-	panic("implement me")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if wg, ok := r.wgs[key]; ok {
+		_ = wg.AddInstance(cfg)
+	}
 }
 
+// AddDataShard starts watchdog of datashard hosts
 func (r *RRouter) AddDataShard(key qdb.ShardKey) error {
+	wg, err := NewShardWatchDog(key.Name, r.routePool)
+	if err != nil {
+		return errors.Wrap(err, "NewShardWatchDog")
+	}
+
+	wg.Run()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.wgs[key] = wg
+
 	return nil
-	// wait to datashard to become available
-	//wg, err := NewShardWatchDog(r.cfg, key.Name, r.routePool)
-	//
-	//if err != nil {
-	//	return errors.Wrap(err, "NewShardWatchDog")
-	//}
-	//
-	//wg.Run()
-	//
-	//r.mu.Lock()
-	//defer r.mu.Unlock()
-	//
-	//r.wgs[key] = wg
-	//
-	//return nil
+}
+
+// ListHosts lists replication status of datashards hosts
+func (r *RRouter) ListHosts() []*conn.HostStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]qdb.ShardKey, 0, len(r.wgs))
+	for key := range r.wgs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	var ret []*conn.HostStatus
+	for _, key := range keys {
+		ret = append(ret, r.wgs[key].Hosts()...)
+	}
+
+	return ret
 }
 
 var _ RequestRouter = &RRouter{}
@@ -0,0 +1,42 @@
+package rrouter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/wal-g/tracelog"
+)
+
+// int8OID is type of sequence values
+const int8OID = 20
+
+// ReplyNextVal replies next value of global sequence, allocated by router
+func (rst *RelayStateImpl) ReplyNextVal(name string) error {
+	val, err := rst.Qr.NextVal(context.TODO(), name)
+	if err != nil {
+		tracelog.ErrorLogger.PrintError(err)
+		return rst.Cl.ReplyErr(fmt.Sprintf("failed to allocate value of sequence %v: %v", name, err))
+	}
+
+	for _, msg := range []pgproto3.BackendMessage{
+		&pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+			{
+				Name:         []byte("spqr_nextval"),
+				DataTypeOID:  int8OID,
+				DataTypeSize: 8,
+				TypeModifier: -1,
+			},
+		}},
+		&pgproto3.DataRow{Values: [][]byte{[]byte(strconv.FormatInt(val, 10))}},
+		&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")},
+		&pgproto3.ReadyForQuery{TxStatus: rst.txStatus},
+	} {
+		if err := rst.Cl.Send(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
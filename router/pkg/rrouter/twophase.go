@@ -0,0 +1,401 @@
+package rrouter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/config"
+	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
+	"github.com/pg-sharding/spqr/pkg/models/transactions"
+	"github.com/pg-sharding/spqr/router/pkg/datashard"
+	"github.com/pg-sharding/spqr/router/pkg/qrouter"
+	"github.com/wal-g/tracelog"
+	"golang.org/x/xerrors"
+)
+
+// gidPrefix marks transactions, prepared by router
+const gidPrefix = "spqr_"
+
+// shardReply is reply of datashard to utility query
+type shardReply struct {
+	shkey kr.ShardKey
+
+	tag    string
+	errmsg *pgproto3.ErrorResponse
+	txst   byte
+	// first row of query result
+	row [][]byte
+}
+
+// execEach executes utility query on datashards in parallel
+// and returns replies of datashards
+func (rst *RelayStateImpl) execEach(shkeys []kr.ShardKey, query string) ([]*shardReply, error) {
+	srv := rst.Cl.Server()
+
+	for _, shkey := range shkeys {
+		if err := srv.SendShard(shkey, &pgproto3.Query{String: query}); err != nil {
+			return nil, err
+		}
+	}
+
+	replies := make([]*shardReply, 0, len(shkeys))
+
+	for _, shkey := range shkeys {
+		reply := &shardReply{shkey: shkey}
+
+		for done := false; !done; {
+			msg, err := srv.ReceiveShard(shkey)
+			if err != nil {
+				return nil, err
+			}
+
+			switch v := msg.(type) {
+			case *pgproto3.DataRow:
+				if reply.row == nil {
+					for _, val := range v.Values {
+						reply.row = append(reply.row, append([]byte(nil), val...))
+					}
+				}
+			case *pgproto3.CommandComplete:
+				reply.tag = string(v.CommandTag)
+			case *pgproto3.ErrorResponse:
+				if reply.errmsg == nil {
+					cp := *v
+					reply.errmsg = &cp
+				}
+			case *pgproto3.ReadyForQuery:
+				reply.txst = v.TxStatus
+				done = true
+			}
+		}
+
+		replies = append(replies, reply)
+	}
+
+	return replies, nil
+}
+
+// isCommitStmt checks if query commits transaction block
+func isCommitStmt(q string) bool {
+	q = strings.ToLower(strings.Join(strings.Fields(strings.TrimRight(strings.TrimSpace(q), ";")), " "))
+
+	switch q {
+	case "commit", "commit work", "commit transaction", "end", "end work", "end transaction":
+		return true
+	default:
+		return false
+	}
+}
+
+// twoPhaseCommit checks if route policy commits
+// transactions, spanning several datashards, with two-phase commit
+func twoPhaseCommit(rule *config.FRRule) bool {
+	switch rule.MultiShardTxPolicy {
+	case config.MultiShardTxTwoPhase:
+		return true
+	default:
+		return false
+	}
+}
+
+// routerGIDPrefix marks transactions, prepared by this router, so router recovers
+// only its own transactions and never aborts transactions, other routers are committing
+func routerGIDPrefix() string {
+	return gidPrefix + config.RouterConfig().RouterID + "_"
+}
+
+// CheckRouterID checks if router id marks prepared transactions of router unambiguously.
+// Id consists of letters, digits and '-', so gid prefix of router never starts with prefix of other router
+func CheckRouterID(id string) error {
+	if id == "" {
+		return xerrors.New("router id is not set")
+	}
+
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return xerrors.Errorf("router id %q contains %q, only letters, digits and '-' are allowed", id, c)
+		}
+	}
+
+	return nil
+}
+
+func newGID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return routerGIDPrefix() + hex.EncodeToString(buf), nil
+}
+
+// relayCommit commits transaction, spanning several datashards, with two-phase commit.
+// Transaction is prepared on every datashard, then commit decision is recorded in qdb
+// and prepared transactions are committed. Transactions, left prepared by router
+// failure, are resolved by RecoverTransactions
+func (rst *RelayStateImpl) relayCommit() (byte, error) {
+	gid, err := newGID()
+	if err != nil {
+		return 0, err
+	}
+
+	replies, err := rst.execEach(rst.ActiveShards, fmt.Sprintf("PREPARE TRANSACTION '%s'", gid))
+	if err != nil {
+		return 0, err
+	}
+
+	var errmsg *pgproto3.ErrorResponse
+	var prepared, unfinished []kr.ShardKey
+	var shards []string
+
+	for _, reply := range replies {
+		shards = append(shards, reply.shkey.Name)
+
+		switch {
+		case reply.errmsg != nil:
+			if errmsg == nil {
+				errmsg = reply.errmsg
+			}
+		case reply.tag == "PREPARE TRANSACTION":
+			prepared = append(prepared, reply.shkey)
+			continue
+		}
+
+		if reply.txst != conn.TXREL {
+			unfinished = append(unfinished, reply.shkey)
+		}
+	}
+
+	status := transactions.Aborted
+	// decision is recorded in qdb
+	recorded := false
+	if len(prepared) == len(replies) {
+		tx, err := rst.Qr.RecordTransaction(context.TODO(), transactions.NewTransaction(gid, shards, transactions.Committed))
+		if err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+			errmsg = &pgproto3.ErrorResponse{
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("failed to record commit of distributed transaction: %v", err),
+			}
+		} else if recorded, status = true, tx.Status; status != transactions.Committed {
+			errmsg = &pgproto3.ErrorResponse{
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("distributed transaction %s is aborted by recovery", gid),
+			}
+		}
+	}
+
+	if len(unfinished) > 0 {
+		if _, err := rst.execEach(unfinished, "ROLLBACK"); err != nil {
+			return 0, err
+		}
+	}
+
+	cmd := "ROLLBACK PREPARED"
+	if status == transactions.Committed {
+		cmd = "COMMIT PREPARED"
+	}
+
+	replies, err = rst.execEach(prepared, fmt.Sprintf("%s '%s'", cmd, gid))
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := true
+	for _, reply := range replies {
+		if reply.errmsg != nil {
+			tracelog.ErrorLogger.Printf("failed to %s %s on datashard %v: %v", cmd, gid, reply.shkey.Name, reply.errmsg.Message)
+			resolved = false
+		}
+	}
+
+	if resolved && recorded {
+		if err := rst.Qr.DeleteTransaction(context.TODO(), gid); err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+		}
+	}
+
+	switch {
+	case status == transactions.Committed:
+		if !resolved {
+			if err := rst.Cl.Send(&pgproto3.NoticeResponse{
+				Severity: "WARNING",
+				Message:  fmt.Sprintf("transaction %s is committed, but not on every datashard yet", gid),
+			}); err != nil {
+				return 0, err
+			}
+		}
+
+		return conn.TXREL, rst.Cl.Send(&pgproto3.CommandComplete{CommandTag: []byte("COMMIT")})
+	case errmsg != nil:
+		return conn.TXREL, rst.Cl.Send(errmsg)
+	default:
+		// transaction block failed on some datashard
+		return conn.TXREL, rst.Cl.Send(&pgproto3.CommandComplete{CommandTag: []byte("ROLLBACK")})
+	}
+}
+
+// preparedTransactions lists transactions, prepared by this router on datashard
+func preparedTransactions(sh datashard.Shard) ([]string, error) {
+	if err := sh.Send(&pgproto3.Query{
+		String: "SELECT gid FROM pg_prepared_xacts WHERE database = current_database()",
+	}); err != nil {
+		return nil, err
+	}
+
+	var gids []string
+	var errmsg string
+
+	for {
+		msg, err := sh.Receive()
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.DataRow:
+			// prefix is matched here, as '_' is wildcard of LIKE
+			if gid := string(v.Values[0]); strings.HasPrefix(gid, routerGIDPrefix()) {
+				gids = append(gids, gid)
+			}
+		case *pgproto3.ErrorResponse:
+			errmsg = v.Message
+		case *pgproto3.ReadyForQuery:
+			if errmsg != "" {
+				return nil, xerrors.Errorf("failed to list prepared transactions: %s", errmsg)
+			}
+			return gids, nil
+		}
+	}
+}
+
+// resolveTransaction commits or aborts prepared transaction on datashard
+func resolveTransaction(sh datashard.Shard, tx *transactions.Transaction) error {
+	cmd := "ROLLBACK PREPARED"
+	if tx.Status == transactions.Committed {
+		cmd = "COMMIT PREPARED"
+	}
+
+	if err := sh.Send(&pgproto3.Query{String: fmt.Sprintf("%s '%s'", cmd, tx.GID)}); err != nil {
+		return err
+	}
+
+	var errmsg string
+
+	for {
+		msg, err := sh.Receive()
+		if err != nil {
+			return err
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.ErrorResponse:
+			errmsg = v.Message
+		case *pgproto3.ReadyForQuery:
+			if errmsg != "" {
+				return xerrors.Errorf("failed to %s %s: %s", cmd, tx.GID, errmsg)
+			}
+			return nil
+		}
+	}
+}
+
+// RecoverTransactions resolves transactions, left prepared on datashards by router failure.
+// Transaction is committed, if commit decision is recorded, and aborted otherwise.
+// Abort decision is recorded first, so router, which is still committing transaction, aborts it too.
+// Decisions are recorded in shared qdb, so recovery is run by SHARDING qrouter only.
+// Router recovers transactions, marked with its id, as they are left by its previous run
+func RecoverTransactions(ctx context.Context, qr qrouter.QueryRouter, mapping map[string]*config.ShardCfg) error {
+	pool := conn.NewConnPool(mapping, config.ReadPolicyPrimaryOnly)
+
+	decisions := map[string]*transactions.Transaction{}
+	// datashards, where prepared transactions are listed and resolved
+	recovered := map[string]bool{}
+	failed := map[string]bool{}
+
+	for name, cfg := range mapping {
+		if cfg.ShType == config.WorldShard {
+			continue
+		}
+
+		shkey := kr.ShardKey{Name: name, RW: true}
+
+		pgi, err := pool.Connection(shkey)
+		if err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+			continue
+		}
+
+		sh, err := datashard.NewShard(shkey, pgi, cfg)
+		if err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+			_ = pgi.Close()
+			continue
+		}
+
+		gids, err := preparedTransactions(sh)
+		if err != nil {
+			tracelog.ErrorLogger.PrintError(err)
+			_ = pgi.Close()
+			continue
+		}
+
+		recovered[name] = true
+
+		for _, gid := range gids {
+			tx, ok := decisions[gid]
+			if !ok {
+				if tx, err = qr.RecordTransaction(ctx, transactions.NewTransaction(gid, nil, transactions.Aborted)); err != nil {
+					return err
+				}
+				decisions[gid] = tx
+			}
+
+			tracelog.InfoLogger.Printf("resolve prepared transaction %s on datashard %s: %s", gid, name, tx.Status)
+
+			if err := resolveTransaction(sh, tx); err != nil {
+				tracelog.ErrorLogger.PrintError(err)
+				failed[gid] = true
+			}
+		}
+
+		_ = pgi.Close()
+	}
+
+	// participants of aborted transaction are unknown, so
+	// its decision is kept, until every datashard is recovered
+	everyRecovered := true
+	for name, cfg := range mapping {
+		if cfg.ShType != config.WorldShard {
+			everyRecovered = everyRecovered && recovered[name]
+		}
+	}
+
+	// decision is kept, until transaction is resolved on every participant
+	for gid, tx := range decisions {
+		if failed[gid] {
+			continue
+		}
+
+		done := everyRecovered
+		if tx.Status == transactions.Committed {
+			done = true
+			for _, name := range tx.Shards {
+				done = done && recovered[name]
+			}
+		}
+
+		if done {
+			if err := qr.DeleteTransaction(ctx, gid); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
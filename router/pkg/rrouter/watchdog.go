@@ -1,126 +1,202 @@
 package rrouter
 
 import (
-	"crypto/tls"
 	"sync"
 	"time"
 
 	"github.com/pg-sharding/spqr/pkg/config"
 	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/pkg/models/kr"
 	"github.com/pg-sharding/spqr/router/pkg/datashard"
 	"github.com/pg-sharding/spqr/router/pkg/route"
 	"github.com/wal-g/tracelog"
 )
 
+// watchdogPeriod is period of datashard hosts checks
+const watchdogPeriod = time.Second * 10
+
 type Watchdog interface {
 	Watch(sh datashard.Shard)
 	AddInstance(cfg *config.InstanceCFG) error
 	Run()
-}
-
-func NewShardWatchDog(tlscfg *tls.Config, shname string, rp RoutePool) (Watchdog, error) {
-
-	cfgs := config.RouterConfig().RouterConfig.ShardMapping[shname].Hosts
-
-	sslmode := config.RouterConfig().RouterConfig.ShardMapping[shname].TLSCfg.SslMode
-
-	hostConns := make([]conn.DBInstance, 0, len(cfgs))
-
-	for _, h := range cfgs {
-
-		i, err := conn.NewInstanceConn(h, tlscfg, sslmode)
 
-		if err != nil {
-			return nil, err
-		}
+	// Hosts lists last reported replication status of datashard hosts
+	Hosts() []*conn.HostStatus
+}
 
-		hostConns = append(hostConns, i)
-	}
+func NewShardWatchDog(shname string, rp RoutePool) (Watchdog, error) {
+	cfg := config.RouterConfig().RouterConfig.ShardMapping[shname]
 
 	return &ShardPrimaryWatchdog{
-		hostConns: hostConns,
-		tlscfg:    tlscfg,
-		sslmode:   sslmode,
+		cfg:       cfg,
+		hosts:     append([]*config.InstanceCFG{}, cfg.Hosts...),
 		rp:        rp,
 		shname:    shname,
+		hostConns: map[string]conn.DBInstance{},
+		statuses:  map[string]*conn.HostStatus{},
 	}, nil
 }
 
+// ShardPrimaryWatchdog periodically checks replication status of datashard hosts.
+// Routes are notified about primary switch and replay delay of replicas
 type ShardPrimaryWatchdog struct {
-	mu      sync.Mutex
-	tlscfg  *tls.Config
-	sslmode string
+	mu  sync.Mutex
+	cfg *config.ShardCfg
 
 	rp RoutePool
 
 	shname string
 
-	hostConns []conn.DBInstance
+	hosts []*config.InstanceCFG
+	// connections to hosts, established on demand
+	hostConns map[string]conn.DBInstance
+
+	primary  string
+	statuses map[string]*conn.HostStatus
 }
 
 func (s *ShardPrimaryWatchdog) AddInstance(cfg *config.InstanceCFG) error {
-	instance, err := conn.NewInstanceConn(cfg, s.tlscfg, s.sslmode)
-	if err != nil {
-		return err
-	}
-
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.hostConns = append(s.hostConns, instance)
+	s.hosts = append(s.hosts, cfg)
 	return nil
 }
 
 func (s *ShardPrimaryWatchdog) Run() {
 	go func() {
-
-		var prvMaster conn.DBInstance
-
 		tracelog.InfoLogger.Printf("datashard watchdog %s started", s.shname)
 
 		for {
+			s.check()
 
-			func() {
+			time.Sleep(watchdogPeriod)
+		}
+	}()
+}
 
-				if prvMaster != nil {
-					if ok, err := prvMaster.CheckRW(); err == nil && ok {
-						// nice
-						return
-					} else if err != nil {
-						tracelog.InfoLogger.Printf("failed to check primary on %v", err)
-					}
-				}
+// connection returns authenticated connection to host
+func (s *ShardPrimaryWatchdog) connection(host *config.InstanceCFG) (conn.DBInstance, error) {
+	if pgi, ok := s.hostConns[host.ConnAddr]; ok {
+		return pgi, nil
+	}
+
+	pgi, err := conn.NewInstanceConn(host, s.cfg.TLSConfig, s.cfg.TLSCfg.SslMode)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := datashard.NewShard(kr.ShardKey{Name: s.shname}, pgi, s.cfg); err != nil {
+		_ = pgi.Close()
+		return nil, err
+	}
+
+	s.hostConns[host.ConnAddr] = pgi
+
+	return pgi, nil
+}
+
+func (s *ShardPrimaryWatchdog) checkHost(host *config.InstanceCFG) *conn.HostStatus {
+	pgi, err := s.connection(host)
+	if err == nil {
+		var status *conn.HostStatus
+		if status, err = conn.ReplicationStatus(s.shname, pgi); err == nil {
+			return status
+		}
+
+		// connection is re-established on next check
+		_ = pgi.Close()
+		delete(s.hostConns, host.ConnAddr)
+	}
 
-				for _, host := range s.hostConns {
-					if ok, err := host.CheckRW(); err == nil && ok && host.Hostname() != prvMaster.Hostname() {
-						prvMaster = host
-						// notify
+	tracelog.InfoLogger.Printf("failed to check host %v of datashard %v: %v", host.ConnAddr, s.shname, err)
 
-						tracelog.InfoLogger.Printf("notifying about new master %v", host.Hostname())
+	return &conn.HostStatus{
+		Shard: s.shname,
+		Host:  host.ConnAddr,
+		Err:   err,
+	}
+}
 
-						_ = s.rp.NotifyRoutes(func(route *route.Route) error {
-							if err := route.ServPool().UpdateHostStatus(s.shname, prvMaster.Hostname(), false); err != nil {
-								return err
-							}
+// check collects replication status of datashard hosts
+// and notifies routes about primary switch and replay delay of replicas
+func (s *ShardPrimaryWatchdog) check() {
+	s.mu.Lock()
+	hosts := append([]*config.InstanceCFG{}, s.hosts...)
+	s.mu.Unlock()
 
-							if err := route.ServPool().UpdateHostStatus(s.shname, host.Hostname(), false); err != nil {
-								return err
-							}
+	statuses := map[string]*conn.HostStatus{}
+	primary := ""
 
-							return nil
-						})
+	for _, host := range hosts {
+		status := s.checkHost(host)
+		statuses[host.ConnAddr] = status
 
-						return
-					} else if err != nil {
-						tracelog.InfoLogger.Printf("failed to check primary on %s", host.Hostname())
-					}
+		if status.Err == nil && status.Primary {
+			primary = host.ConnAddr
+		}
+	}
+
+	s.mu.Lock()
+	prvPrimary := s.primary
+	if primary != "" {
+		s.primary = primary
+	}
+	s.statuses = statuses
+	s.mu.Unlock()
+
+	switched := primary != "" && primary != prvPrimary
+	if switched {
+		tracelog.InfoLogger.Printf("notifying about new primary %v of datashard %v", primary, s.shname)
+	}
+
+	_ = s.rp.NotifyRoutes(func(route *route.Route) error {
+		if switched {
+			if prvPrimary != "" {
+				if err := route.ServPool().UpdateHostStatus(s.shname, prvPrimary, false); err != nil {
+					return err
 				}
-			}()
+			}
 
-			time.Sleep(time.Second * 10)
+			if err := route.ServPool().UpdateHostStatus(s.shname, primary, true); err != nil {
+				return err
+			}
 		}
 
-	}()
+		for _, status := range statuses {
+			if status.Err == nil && status.Primary {
+				continue
+			}
+
+			// unreachable host may lag behind primary, once it is reachable again
+			lag := status.Lag
+			if status.Err != nil {
+				lag = conn.UnknownLag
+			}
+
+			if err := route.ServPool().UpdateHostLag(s.shname, status.Host, lag); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Hosts lists statuses in order of datashard hosts.
+// Hosts, which are not checked yet, are not listed
+func (s *ShardPrimaryWatchdog) Hosts() []*conn.HostStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ret := make([]*conn.HostStatus, 0, len(s.hosts))
+	for _, host := range s.hosts {
+		if status, ok := s.statuses[host.ConnAddr]; ok {
+			ret = append(ret, status)
+		}
+	}
+
+	return ret
 }
 
 func (s *ShardPrimaryWatchdog) Watch(sh datashard.Shard) {
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgproto3/v2"
+	"golang.org/x/xerrors"
+)
+
+// aggregates, which are combined from partial aggregates of datashards
+const (
+	AggregateCount = "count"
+	AggregateSum   = "sum"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	// AVG is queried from datashards as SUM and COUNT
+	AggregateAvg = "avg"
+)
+
+// Aggregate is aggregate column of grouped result
+type Aggregate struct {
+	Column int
+	Func   string
+
+	// column of partial row count of AVG
+	CountColumn int
+}
+
+// avgScale is minimal number of fractional digits of numeric AVG, as in PostgreSQL
+const avgScale = 16
+
+func isFloat(field pgproto3.FieldDescription) bool {
+	return field.DataTypeOID == float4OID || field.DataTypeOID == float8OID
+}
+
+// aggregateFields describes grouped result columns:
+// AVG of non-float values is numeric, while SUM of them is not always
+func aggregateFields(plan *MergePlan, fields []pgproto3.FieldDescription) []pgproto3.FieldDescription {
+	ret := append([]pgproto3.FieldDescription{}, fields...)
+
+	for _, agg := range plan.Aggregates {
+		if agg.Func != AggregateAvg || agg.Column >= len(ret) || isFloat(ret[agg.Column]) {
+			continue
+		}
+
+		ret[agg.Column].DataTypeOID = numericOID
+		ret[agg.Column].DataTypeSize = -1
+		ret[agg.Column].TypeModifier = -1
+	}
+
+	return ret
+}
+
+// groupKey encodes group columns values of row
+func groupKey(row *pgproto3.DataRow, columns []int) string {
+	var buf bytes.Buffer
+
+	for _, column := range columns {
+		val := row.Values[column]
+		if val == nil {
+			buf.WriteByte(0)
+			continue
+		}
+
+		buf.WriteByte(1)
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(val)))
+		buf.Write(val)
+	}
+
+	return buf.String()
+}
+
+// aggregate combines row with previous rows of its group
+func (m *rowMerger) aggregate(row *pgproto3.DataRow) {
+	key := groupKey(row, m.plan.GroupColumns)
+
+	acc, ok := m.groups[key]
+	if !ok {
+		m.groups[key] = row
+		m.groupOrder = append(m.groupOrder, row)
+		return
+	}
+
+	for _, agg := range m.plan.Aggregates {
+		var err error
+
+		switch agg.Func {
+		case AggregateCount, AggregateSum:
+			acc.Values[agg.Column], err = addValues(m.desc[agg.Column], acc.Values[agg.Column], row.Values[agg.Column])
+		case AggregateAvg:
+			acc.Values[agg.Column], err = addValues(m.desc[agg.Column], acc.Values[agg.Column], row.Values[agg.Column])
+			if err == nil {
+				acc.Values[agg.CountColumn], err = addValues(m.desc[agg.CountColumn], acc.Values[agg.CountColumn], row.Values[agg.CountColumn])
+			}
+		case AggregateMin, AggregateMax:
+			lhs, rhs := acc.Values[agg.Column], row.Values[agg.Column]
+			if rhs == nil {
+				break
+			}
+
+			res := 0
+			if lhs != nil {
+				res = compareValues(m.desc[agg.Column], rhs, lhs)
+			}
+			if lhs == nil || agg.Func == AggregateMin && res < 0 || agg.Func == AggregateMax && res > 0 {
+				acc.Values[agg.Column] = rhs
+			}
+		}
+
+		if err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// finishGroups computes AVG of every group and sorts groups by sort keys
+func (m *rowMerger) finishGroups() {
+	rows := m.groupOrder
+
+	for _, row := range rows {
+		for _, agg := range m.plan.Aggregates {
+			if agg.Func != AggregateAvg {
+				continue
+			}
+
+			var err error
+			row.Values[agg.Column], err = avgValue(m.desc[agg.Column], row.Values[agg.Column], row.Values[agg.CountColumn])
+			if err != nil && m.err == nil {
+				m.err = err
+			}
+		}
+	}
+
+	if len(m.columns) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return m.less(rows[i], rows[j])
+		})
+	}
+
+	m.queues[0] = rows
+	m.groups = nil
+	m.groupOrder = nil
+}
+
+// fractionDigits returns number of digits after decimal point
+func fractionDigits(val []byte) int {
+	if i := bytes.IndexByte(val, '.'); i >= 0 {
+		return len(val) - i - 1
+	}
+
+	return 0
+}
+
+// addValues sums partial aggregates in text format. Nulls are skipped
+func addValues(field pgproto3.FieldDescription, lhs, rhs []byte) ([]byte, error) {
+	switch {
+	case lhs == nil:
+		return rhs, nil
+	case rhs == nil:
+		return lhs, nil
+	}
+
+	if isFloat(field) {
+		lnum, lerr := strconv.ParseFloat(string(lhs), 64)
+		rnum, rerr := strconv.ParseFloat(string(rhs), 64)
+		if lerr != nil || rerr != nil {
+			return nil, xerrors.Errorf("failed to add partial aggregates %s and %s", lhs, rhs)
+		}
+
+		return []byte(strconv.FormatFloat(lnum+rnum, 'g', -1, 64)), nil
+	}
+
+	lnum, lok := new(big.Rat).SetString(string(lhs))
+	rnum, rok := new(big.Rat).SetString(string(rhs))
+	if !lok || !rok {
+		return nil, xerrors.Errorf("failed to add partial aggregates %s and %s", lhs, rhs)
+	}
+
+	scale := fractionDigits(lhs)
+	if s := fractionDigits(rhs); s > scale {
+		scale = s
+	}
+
+	return []byte(lnum.Add(lnum, rnum).FloatString(scale)), nil
+}
+
+// avgValue computes AVG as sum / count. It is null for group without values
+func avgValue(field pgproto3.FieldDescription, sum, count []byte) ([]byte, error) {
+	if sum == nil || count == nil {
+		return nil, nil
+	}
+
+	cnt, ok := new(big.Rat).SetString(string(count))
+	if !ok {
+		return nil, xerrors.Errorf("failed to compute average with row count %s", count)
+	}
+	if cnt.Sign() == 0 {
+		return nil, nil
+	}
+
+	if isFloat(field) {
+		num, err := strconv.ParseFloat(string(sum), 64)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to compute average of %s", sum)
+		}
+		div, _ := cnt.Float64()
+
+		return []byte(strconv.FormatFloat(num/div, 'g', -1, 64)), nil
+	}
+
+	num, ok := new(big.Rat).SetString(string(sum))
+	if !ok {
+		return nil, xerrors.Errorf("failed to compute average of %s", sum)
+	}
+
+	scale := fractionDigits(sum)
+	if scale < avgScale {
+		scale = avgScale
+	}
+
+	return []byte(num.Quo(num, cnt).FloatString(scale)), nil
+}
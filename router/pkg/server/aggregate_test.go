@@ -0,0 +1,141 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+)
+
+func TestAddValues(t *testing.T) {
+	int8Field := pgproto3.FieldDescription{DataTypeOID: int8OID}
+	numericField := pgproto3.FieldDescription{DataTypeOID: numericOID}
+	float8Field := pgproto3.FieldDescription{DataTypeOID: float8OID}
+
+	for _, tt := range []struct {
+		name     string
+		field    pgproto3.FieldDescription
+		lhs, rhs []byte
+		want     []byte
+	}{
+		{name: "integers", field: int8Field, lhs: []byte("2"), rhs: []byte("3"), want: []byte("5")},
+		{name: "beyond int64", field: numericField, lhs: []byte("9223372036854775807"), rhs: []byte("1"), want: []byte("9223372036854775808")},
+		{name: "scale of numeric is kept", field: numericField, lhs: []byte("1.10"), rhs: []byte("2.2"), want: []byte("3.30")},
+		{name: "floats", field: float8Field, lhs: []byte("0.5"), rhs: []byte("0.25"), want: []byte("0.75")},
+		{name: "null is skipped", field: int8Field, lhs: nil, rhs: []byte("3"), want: []byte("3")},
+		{name: "nulls", field: int8Field, lhs: nil, rhs: nil, want: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addValues(tt.field, tt.lhs, tt.rhs)
+			if err != nil {
+				t.Fatalf("addValues(%q, %q): %v", tt.lhs, tt.rhs, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addValues(%q, %q) = %q, want %q", tt.lhs, tt.rhs, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := addValues(int8Field, []byte("abc"), []byte("1")); err == nil {
+		t.Errorf("addValues of text succeeded, want error")
+	}
+}
+
+func TestAvgValue(t *testing.T) {
+	numericField := pgproto3.FieldDescription{DataTypeOID: numericOID}
+	float8Field := pgproto3.FieldDescription{DataTypeOID: float8OID}
+
+	for _, tt := range []struct {
+		name       string
+		field      pgproto3.FieldDescription
+		sum, count []byte
+		want       []byte
+	}{
+		{name: "numeric has scale of PostgreSQL", field: numericField, sum: []byte("10"), count: []byte("4"), want: []byte("2.5000000000000000")},
+		{name: "repeating fraction", field: numericField, sum: []byte("1"), count: []byte("3"), want: []byte("0.3333333333333333")},
+		{name: "float", field: float8Field, sum: []byte("1"), count: []byte("4"), want: []byte("0.25")},
+		{name: "no rows", field: numericField, sum: []byte("0"), count: []byte("0"), want: nil},
+		{name: "null sum", field: numericField, sum: nil, count: []byte("2"), want: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := avgValue(tt.field, tt.sum, tt.count)
+			if err != nil {
+				t.Fatalf("avgValue(%q, %q): %v", tt.sum, tt.count, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("avgValue(%q, %q) = %q, want %q", tt.sum, tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeAggregates(t *testing.T) {
+	// SELECT grp, count(*), sum(val), min(val), max(val), avg(val) ... GROUP BY grp ORDER BY grp,
+	// where avg is queried as sum and hidden count
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("grp"), DataTypeOID: int4OID},
+		{Name: []byte("count"), DataTypeOID: int8OID},
+		{Name: []byte("sum"), DataTypeOID: int8OID},
+		{Name: []byte("min"), DataTypeOID: int4OID},
+		{Name: []byte("max"), DataTypeOID: int4OID},
+		{Name: []byte("avg"), DataTypeOID: int8OID},
+		{Name: []byte("avg_count"), DataTypeOID: int8OID},
+	}}
+
+	plan := &MergePlan{
+		SortKeys:      []SortKey{{Column: 0}},
+		HiddenColumns: 1,
+		Grouped:       true,
+		GroupColumns:  []int{0},
+		Aggregates: []Aggregate{
+			{Column: 1, Func: AggregateCount},
+			{Column: 2, Func: AggregateSum},
+			{Column: 3, Func: AggregateMin},
+			{Column: 4, Func: AggregateMax},
+			{Column: 5, Func: AggregateAvg, CountColumn: 6},
+		},
+	}
+
+	got := mergeRows(t, plan, desc, [][]*pgproto3.DataRow{
+		{dataRow("2", "1", "5", "5", "5", "5", "1"), dataRow("1", "2", "3", "1", "2", "3", "2")},
+		{dataRow("1", "1", "10", "10", "10", "10", "1"), dataRow("3", "1", "NULL", "NULL", "NULL", "NULL", "0")},
+	})
+
+	want := [][]string{
+		{"1", "3", "13", "1", "10", "4.3333333333333333"},
+		{"2", "1", "5", "5", "5", "5.0000000000000000"},
+		{"3", "1", "NULL", "NULL", "NULL", "NULL"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged groups %q, want %q", got, want)
+	}
+}
+
+func TestReceiveAggregatesWithoutDescribe(t *testing.T) {
+	// SELECT count(*), sum(val), max(val) ... executed via extended protocol without Describe
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("count"), DataTypeOID: int8OID},
+		{Name: []byte("sum"), DataTypeOID: int8OID},
+		{Name: []byte("max"), DataTypeOID: int4OID},
+	}}
+
+	plan := &MergePlan{
+		Grouped: true,
+		Aggregates: []Aggregate{
+			{Column: 0, Func: AggregateCount},
+			{Column: 1, Func: AggregateSum},
+			{Column: 2, Func: AggregateMax},
+		},
+		ImplicitDescribe: true,
+	}
+
+	got := receiveReplies(t, plan,
+		extendedReplies(desc, dataRow("2", "6", "4")),
+		extendedReplies(desc, dataRow("1", "3", "3")),
+	)
+
+	want := []string{"BindComplete", "3,9,4", "SELECT 1", "ReadyForQuery"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replies %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/conn"
+	"github.com/pg-sharding/spqr/router/pkg/datashard"
+	"github.com/wal-g/tracelog"
+)
+
+type shardMessage struct {
+	// index of datashard in active shards
+	idx int
+	msg pgproto3.BackendMessage
+	err error
+	// message is synchronization point of datashard replies
+	sync bool
+}
+
+// replyPhase reads replies of every datashard in background
+// until each of them reaches synchronization point
+type replyPhase struct {
+	ch   chan shardMessage
+	stop chan struct{}
+
+	syncMsgs []pgproto3.BackendMessage
+	synced   int
+}
+
+// copyStreamed copies messages, which are relayed as they arrive.
+// pgproto3 reuses message structs and buffers between Receive calls
+func copyStreamed(msg pgproto3.BackendMessage) (pgproto3.BackendMessage, bool) {
+	switch v := msg.(type) {
+	case *pgproto3.DataRow:
+		values := make([][]byte, len(v.Values))
+		for i, val := range v.Values {
+			if val != nil {
+				values[i] = append([]byte{}, val...)
+			}
+		}
+		return &pgproto3.DataRow{Values: values}, true
+	case *pgproto3.CopyData:
+		return &pgproto3.CopyData{Data: append([]byte{}, v.Data...)}, true
+	case *pgproto3.NoticeResponse:
+		cp := *v
+		return &cp, true
+	case *pgproto3.ParameterStatus:
+		cp := *v
+		return &cp, true
+	default:
+		return msg, false
+	}
+}
+
+func receivePhase(idx int, shard datashard.Shard, phase *replyPhase) {
+	for {
+		msg, err := shard.Receive()
+
+		smsg := shardMessage{idx: idx, err: err}
+		if err == nil {
+			var streamed bool
+			smsg.msg, streamed = copyStreamed(msg)
+			smsg.sync = !streamed
+		}
+
+		select {
+		case phase.ch <- smsg:
+		case <-phase.stop:
+			return
+		}
+
+		if err != nil || smsg.sync {
+			return
+		}
+	}
+}
+
+func (m *MultiShardServer) startPhase() {
+	m.phase = &replyPhase{
+		ch:       make(chan shardMessage),
+		stop:     make(chan struct{}),
+		syncMsgs: make([]pgproto3.BackendMessage, len(m.activeShards)),
+	}
+
+	for i, shard := range m.activeShards {
+		go receivePhase(i, shard, m.phase)
+	}
+}
+
+func (m *MultiShardServer) stopPhase() {
+	close(m.phase.stop)
+	m.phase = nil
+}
+
+// merge composes single reply of datashards synchronization points
+func (m *MultiShardServer) merge(msgs []pgproto3.BackendMessage) (pgproto3.BackendMessage, error) {
+	tracelog.InfoLogger.Printf("compute multi server msgs from %T", msgs[0])
+
+	for _, msg := range msgs {
+		if errmsg, ok := msg.(*pgproto3.ErrorResponse); ok {
+			cp := *errmsg
+			return m.fail(msgs, &cp)
+		}
+	}
+
+	for i := range msgs {
+		if reflect.TypeOf(msgs[0]) != reflect.TypeOf(msgs[i]) {
+			return m.fail(msgs, &pgproto3.ErrorResponse{
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("got messages with different types from multiconnection %T, %T", msgs[0], msgs[i]),
+			})
+		}
+	}
+
+	switch v := msgs[0].(type) {
+	case *pgproto3.CommandComplete:
+		tags := make([][]byte, 0, len(msgs))
+		for _, msg := range msgs {
+			tags = append(tags, msg.(*pgproto3.CommandComplete).CommandTag)
+		}
+
+		if m.merger != nil {
+			relayed, err := m.merger.relayed, m.merger.err
+			m.resetMerge()
+
+			if err != nil {
+				return m.fail(msgs, &pgproto3.ErrorResponse{
+					Severity: "ERROR",
+					Message:  err.Error(),
+				})
+			}
+
+			return &pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", relayed))}, nil
+		}
+
+		return &pgproto3.CommandComplete{CommandTag: MergeCommandTags(tags)}, nil
+	case *pgproto3.RowDescription:
+		for _, msg := range msgs[1:] {
+			if !compatibleRows(v, msg.(*pgproto3.RowDescription)) {
+				return m.fail(msgs, &pgproto3.ErrorResponse{
+					Severity: "ERROR",
+					Message:  "datashards returned incompatible row descriptions",
+				})
+			}
+		}
+
+		if m.plan != nil {
+			merger, err := newRowMerger(m.plan, v, len(msgs))
+			if err != nil {
+				return m.fail(msgs, &pgproto3.ErrorResponse{
+					Severity: "ERROR",
+					Message:  err.Error(),
+				})
+			}
+
+			m.merger = merger
+			if m.plan.ImplicitDescribe {
+				return m.Receive()
+			}
+			return merger.visibleRows(), nil
+		}
+
+		return v, nil
+	case *pgproto3.ReadyForQuery:
+		m.resetMerge()
+
+		txst := make([]byte, 0, len(msgs))
+		for _, msg := range msgs {
+			txst = append(txst, msg.(*pgproto3.ReadyForQuery).TxStatus)
+		}
+
+		return &pgproto3.ReadyForQuery{TxStatus: mergeTxStatus(txst)}, nil
+	default:
+		// ParseComplete, BindComplete, NoData, CopyOutResponse, CopyDone etc.
+		// are identical on every shard
+		return v, nil
+	}
+}
+
+// fail skips replies of datashards up to ReadyForQuery, so error
+// is relayed to client as single ErrorResponse followed by ReadyForQuery
+func (m *MultiShardServer) fail(msgs []pgproto3.BackendMessage, errmsg *pgproto3.ErrorResponse) (pgproto3.BackendMessage, error) {
+	m.resetMerge()
+
+	txst := make([]byte, 0, len(msgs))
+
+	for i, msg := range msgs {
+		for {
+			if v, ok := msg.(*pgproto3.ReadyForQuery); ok {
+				txst = append(txst, v.TxStatus)
+				break
+			}
+
+			var err error
+			if msg, err = m.activeShards[i].Receive(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	m.pending = append(m.pending, &pgproto3.ReadyForQuery{TxStatus: mergeTxStatus(txst)})
+
+	return errmsg, nil
+}
+
+// compatibleRows checks if rows of both descriptions may be relayed as rows of one result
+func compatibleRows(lhs, rhs *pgproto3.RowDescription) bool {
+	if len(lhs.Fields) != len(rhs.Fields) {
+		return false
+	}
+
+	for i := range lhs.Fields {
+		if lhs.Fields[i].DataTypeOID != rhs.Fields[i].DataTypeOID || lhs.Fields[i].Format != rhs.Fields[i].Format {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeTxStatus returns failed status, if transaction failed on any datashard,
+// then in transaction status, if any datashard is in transaction
+func mergeTxStatus(txst []byte) byte {
+	ret := byte(conn.TXREL)
+
+	for _, st := range txst {
+		switch st {
+		case conn.TXERR:
+			return conn.TXERR
+		case conn.NOTXREL:
+			ret = conn.NOTXREL
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+
+	"github.com/jackc/pgproto3/v2"
+	"golang.org/x/xerrors"
+)
+
+// MergePlan describes how rows of datashards are merged into single result.
+// Rows of every datashard are expected to be sorted by sort keys
+type MergePlan struct {
+	SortKeys []SortKey
+
+	// global LIMIT, nil if not set
+	Limit  *uint64
+	Offset uint64
+
+	// number of trailing columns, which are added to query only to sort
+	// or aggregate rows
+	HiddenColumns int
+
+	// rows of datashards with equal group columns are combined into one
+	// by aggregates, if result is grouped
+	Grouped      bool
+	GroupColumns []int
+	Aggregates   []Aggregate
+
+	// row description of result is requested by router, not by client,
+	// so it is used to merge rows, but not relayed
+	ImplicitDescribe bool
+}
+
+// SortKey is ORDER BY key of result. Column is looked up by name,
+// if its position is unknown
+type SortKey struct {
+	Column int
+	Name   string
+	Desc   bool
+}
+
+// type OIDs of PostgreSQL numeric types
+const (
+	int8OID    = 20
+	int2OID    = 21
+	int4OID    = 23
+	oidOID     = 26
+	float4OID  = 700
+	float8OID  = 701
+	numericOID = 1700
+)
+
+// type OIDs of PostgreSQL text types, which are sorted by collation
+const (
+	textOID    = 25
+	bpcharOID  = 1042
+	varcharOID = 1043
+)
+
+// rowMerger merges sorted rows of datashards
+type rowMerger struct {
+	plan *MergePlan
+
+	// descriptions of merged result columns, including hidden ones
+	desc []pgproto3.FieldDescription
+	// sort key columns and their descriptions
+	columns []int
+	fields  []pgproto3.FieldDescription
+
+	// received, but not merged rows of every datashard
+	queues [][]*pgproto3.DataRow
+
+	// groups of grouped result in order of arrival
+	groups     map[string]*pgproto3.DataRow
+	groupOrder []*pgproto3.DataRow
+	aggregated bool
+	// first error of aggregation
+	err error
+
+	// rows, merged so far and relayed to client
+	merged  uint64
+	relayed uint64
+}
+
+func newRowMerger(plan *MergePlan, desc *pgproto3.RowDescription, shards int) (*rowMerger, error) {
+	m := &rowMerger{
+		plan:   plan,
+		desc:   aggregateFields(plan, desc.Fields),
+		queues: make([][]*pgproto3.DataRow, shards),
+		groups: map[string]*pgproto3.DataRow{},
+	}
+
+	for _, key := range plan.SortKeys {
+		column := key.Column
+		if key.Name != "" {
+			column = -1
+			for i, field := range desc.Fields {
+				if string(field.Name) == key.Name {
+					column = i
+					break
+				}
+			}
+		}
+
+		if column < 0 || column >= len(desc.Fields) {
+			return nil, xerrors.Errorf("failed to resolve sort column %v", key.Name)
+		}
+
+		// collation of datashards is unknown, so text is not merged bytewise
+		switch m.desc[column].DataTypeOID {
+		case textOID, bpcharOID, varcharOID:
+			return nil, xerrors.Errorf("sorting by text column %s is not supported for query to several datashards", m.desc[column].Name)
+		}
+
+		m.columns = append(m.columns, column)
+		m.fields = append(m.fields, m.desc[column])
+	}
+
+	for _, agg := range plan.Aggregates {
+		if agg.Column >= len(desc.Fields) || agg.CountColumn >= len(desc.Fields) {
+			return nil, xerrors.New("failed to resolve aggregate column")
+		}
+		if desc.Fields[agg.Column].Format != 0 {
+			return nil, xerrors.New("aggregate in binary format is not supported for query to several datashards")
+		}
+	}
+
+	return m, nil
+}
+
+// visibleRows describes merged result without hidden columns
+func (m *rowMerger) visibleRows() *pgproto3.RowDescription {
+	return &pgproto3.RowDescription{
+		Fields: m.desc[:len(m.desc)-m.plan.HiddenColumns],
+	}
+}
+
+func (m *rowMerger) push(idx int, row *pgproto3.DataRow) {
+	if m.plan.Grouped {
+		m.aggregate(row)
+		return
+	}
+
+	m.queues[idx] = append(m.queues[idx], row)
+}
+
+// next returns next row of merged result. Row is returned only when
+// every datashard, which is not exhausted yet, has received rows.
+// Grouped result is returned, when every datashard is exhausted
+func (m *rowMerger) next(exhausted func(idx int) bool) (*pgproto3.DataRow, bool) {
+	if m.plan.Grouped && !m.aggregated {
+		for i := range m.queues {
+			if !exhausted(i) {
+				return nil, false
+			}
+		}
+
+		m.finishGroups()
+		m.aggregated = true
+	}
+
+	if m.err != nil {
+		return nil, false
+	}
+
+	for {
+		best := -1
+
+		for i, queue := range m.queues {
+			if len(queue) == 0 {
+				if !exhausted(i) && len(m.columns) > 0 {
+					return nil, false
+				}
+				continue
+			}
+
+			if best < 0 || m.less(queue[0], m.queues[best][0]) {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			return nil, false
+		}
+
+		row := m.queues[best][0]
+		m.queues[best] = m.queues[best][1:]
+
+		m.merged++
+		if m.merged <= m.plan.Offset {
+			continue
+		}
+		if m.plan.Limit != nil && m.relayed >= *m.plan.Limit {
+			// rest of rows is read, but not relayed
+			continue
+		}
+
+		m.relayed++
+		row.Values = row.Values[:len(row.Values)-m.plan.HiddenColumns]
+
+		return row, true
+	}
+}
+
+func (m *rowMerger) less(lhs, rhs *pgproto3.DataRow) bool {
+	for i, column := range m.columns {
+		res := compareValues(m.fields[i], lhs.Values[column], rhs.Values[column])
+		if m.plan.SortKeys[i].Desc {
+			res = -res
+		}
+
+		if res != 0 {
+			return res < 0
+		}
+	}
+
+	return false
+}
+
+// compareValues compares column values like PostgreSQL does by default:
+// nulls are larger than any value, numbers are compared numerically,
+// other values are compared bytewise. Text values are not compared,
+// as their order depends on collation
+func compareValues(field pgproto3.FieldDescription, lhs, rhs []byte) int {
+	switch {
+	case lhs == nil && rhs == nil:
+		return 0
+	case lhs == nil:
+		return 1
+	case rhs == nil:
+		return -1
+	}
+
+	if lnum, ok := numericValue(field, lhs); ok {
+		if rnum, ok := numericValue(field, rhs); ok {
+			return lnum.Cmp(rnum)
+		}
+	}
+
+	return bytes.Compare(lhs, rhs)
+}
+
+func numericValue(field pgproto3.FieldDescription, val []byte) (*big.Float, bool) {
+	if field.Format == 0 {
+		switch field.DataTypeOID {
+		case int2OID, int4OID, int8OID, oidOID, float4OID, float8OID, numericOID:
+			ret, _, err := big.ParseFloat(string(val), 10, 256, big.ToNearestEven)
+			return ret, err == nil
+		default:
+			return nil, false
+		}
+	}
+
+	switch {
+	case field.DataTypeOID == int2OID && len(val) == 2:
+		return big.NewFloat(float64(int16(binary.BigEndian.Uint16(val)))), true
+	case field.DataTypeOID == int4OID && len(val) == 4:
+		return big.NewFloat(float64(int32(binary.BigEndian.Uint32(val)))), true
+	case field.DataTypeOID == int8OID && len(val) == 8:
+		return new(big.Float).SetInt64(int64(binary.BigEndian.Uint64(val))), true
+	case field.DataTypeOID == oidOID && len(val) == 4:
+		return big.NewFloat(float64(binary.BigEndian.Uint32(val))), true
+	case field.DataTypeOID == float4OID && len(val) == 4:
+		f := math.Float32frombits(binary.BigEndian.Uint32(val))
+		return numericFloat(float64(f))
+	case field.DataTypeOID == float8OID && len(val) == 8:
+		return numericFloat(math.Float64frombits(binary.BigEndian.Uint64(val)))
+	default:
+		return nil, false
+	}
+}
+
+func numericFloat(f float64) (*big.Float, bool) {
+	if math.IsNaN(f) {
+		return nil, false
+	}
+
+	return big.NewFloat(f), true
+}
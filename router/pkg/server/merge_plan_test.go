@@ -0,0 +1,205 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+)
+
+func dataRow(values ...string) *pgproto3.DataRow {
+	row := &pgproto3.DataRow{}
+	for _, val := range values {
+		if val == "NULL" {
+			row.Values = append(row.Values, nil)
+			continue
+		}
+		row.Values = append(row.Values, []byte(val))
+	}
+	return row
+}
+
+func rowValues(row *pgproto3.DataRow) []string {
+	var ret []string
+	for _, val := range row.Values {
+		if val == nil {
+			ret = append(ret, "NULL")
+			continue
+		}
+		ret = append(ret, string(val))
+	}
+	return ret
+}
+
+// mergeRows merges rows of every datashard, which are received at once
+func mergeRows(t *testing.T, plan *MergePlan, desc *pgproto3.RowDescription, shards [][]*pgproto3.DataRow) [][]string {
+	t.Helper()
+
+	m, err := newRowMerger(plan, desc, len(shards))
+	if err != nil {
+		t.Fatalf("newRowMerger: %v", err)
+	}
+
+	for idx, rows := range shards {
+		for _, row := range rows {
+			m.push(idx, row)
+		}
+	}
+
+	var ret [][]string
+	for {
+		row, ok := m.next(func(int) bool { return true })
+		if !ok {
+			break
+		}
+		ret = append(ret, rowValues(row))
+	}
+
+	if m.err != nil {
+		t.Fatalf("merge: %v", m.err)
+	}
+
+	return ret
+}
+
+func TestCompareValues(t *testing.T) {
+	int8Field := pgproto3.FieldDescription{DataTypeOID: int8OID}
+	numericField := pgproto3.FieldDescription{DataTypeOID: numericOID}
+	int4Binary := pgproto3.FieldDescription{DataTypeOID: int4OID, Format: 1}
+	byteaField := pgproto3.FieldDescription{DataTypeOID: 17}
+
+	for _, tt := range []struct {
+		name     string
+		field    pgproto3.FieldDescription
+		lhs, rhs []byte
+		want     int
+	}{
+		{name: "integers numerically", field: int8Field, lhs: []byte("9"), rhs: []byte("10"), want: -1},
+		{name: "negative integers", field: int8Field, lhs: []byte("-10"), rhs: []byte("-9"), want: -1},
+		{name: "numeric", field: numericField, lhs: []byte("1.50"), rhs: []byte("1.5"), want: 0},
+		{name: "binary integers", field: int4Binary, lhs: []byte{0xff, 0xff, 0xff, 0xff}, rhs: []byte{0, 0, 0, 1}, want: -1},
+		{name: "bytes", field: byteaField, lhs: []byte("b"), rhs: []byte("ab"), want: 1},
+		{name: "null is larger than value", field: int8Field, lhs: nil, rhs: []byte("1"), want: 1},
+		{name: "value is less than null", field: int8Field, lhs: []byte("1"), rhs: nil, want: -1},
+		{name: "nulls are equal", field: int8Field, lhs: nil, rhs: nil, want: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareValues(tt.field, tt.lhs, tt.rhs); got != tt.want {
+				t.Errorf("compareValues(%q, %q) = %d, want %d", tt.lhs, tt.rhs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSortedRows(t *testing.T) {
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: int8OID},
+		{Name: []byte("val"), DataTypeOID: int4OID},
+	}}
+
+	// rows of every datashard are sorted by id and by val
+	shards := func() [][]*pgproto3.DataRow {
+		return [][]*pgproto3.DataRow{
+			{dataRow("1", "10"), dataRow("4", "40"), dataRow("10", "100")},
+			{dataRow("2", "20"), dataRow("3", "30"), dataRow("NULL", "NULL")},
+		}
+	}
+
+	limit := uint64(2)
+
+	for _, tt := range []struct {
+		name string
+		plan *MergePlan
+		want [][]string
+	}{
+		{
+			name: "ascending",
+			plan: &MergePlan{SortKeys: []SortKey{{Column: 0}}},
+			want: [][]string{{"1", "10"}, {"2", "20"}, {"3", "30"}, {"4", "40"}, {"10", "100"}, {"NULL", "NULL"}},
+		},
+		{
+			name: "sort key by name",
+			plan: &MergePlan{SortKeys: []SortKey{{Name: "val"}}},
+			want: [][]string{{"1", "10"}, {"2", "20"}, {"3", "30"}, {"4", "40"}, {"10", "100"}, {"NULL", "NULL"}},
+		},
+		{
+			name: "limit and offset",
+			plan: &MergePlan{SortKeys: []SortKey{{Column: 0}}, Limit: &limit, Offset: 1},
+			want: [][]string{{"2", "20"}, {"3", "30"}},
+		},
+		{
+			name: "hidden sort column",
+			plan: &MergePlan{SortKeys: []SortKey{{Column: 1}}, HiddenColumns: 1, Limit: &limit, Offset: 3},
+			want: [][]string{{"4"}, {"10"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeRows(t, tt.plan, desc, shards()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged rows %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDescendingRows(t *testing.T) {
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: int8OID},
+	}}
+
+	// nulls are first in descending order
+	got := mergeRows(t, &MergePlan{SortKeys: []SortKey{{Column: 0, Desc: true}}}, desc, [][]*pgproto3.DataRow{
+		{dataRow("NULL"), dataRow("10"), dataRow("1")},
+		{dataRow("9"), dataRow("2")},
+	})
+
+	want := [][]string{{"NULL"}, {"10"}, {"9"}, {"2"}, {"1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged rows %q, want %q", got, want)
+	}
+}
+
+func TestMergeWaitsForEveryDatashard(t *testing.T) {
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: int8OID},
+	}}
+
+	m, err := newRowMerger(&MergePlan{SortKeys: []SortKey{{Column: 0}}}, desc, 2)
+	if err != nil {
+		t.Fatalf("newRowMerger: %v", err)
+	}
+
+	m.push(0, dataRow("5"))
+
+	if row, ok := m.next(func(int) bool { return false }); ok {
+		t.Fatalf("row %q is merged before second datashard replied", rowValues(row))
+	}
+
+	m.push(1, dataRow("3"))
+
+	row, ok := m.next(func(int) bool { return false })
+	if !ok || string(row.Values[0]) != "3" {
+		t.Fatalf("merged row %v, %v, want 3", row, ok)
+	}
+}
+
+func TestMergeRejectsTextSortKey(t *testing.T) {
+	for _, oid := range []uint32{textOID, varcharOID, bpcharOID} {
+		desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+			{Name: []byte("name"), DataTypeOID: oid},
+		}}
+
+		if _, err := newRowMerger(&MergePlan{SortKeys: []SortKey{{Column: 0}}}, desc, 2); err == nil {
+			t.Errorf("sort by column of type %d is merged, want error", oid)
+		}
+	}
+}
+
+func TestMergeUnknownSortColumn(t *testing.T) {
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: int8OID},
+	}}
+
+	if _, err := newRowMerger(&MergePlan{SortKeys: []SortKey{{Name: "missing"}}}, desc, 2); err == nil {
+		t.Errorf("sort by missing column is merged, want error")
+	}
+}
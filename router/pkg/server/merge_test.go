@@ -0,0 +1,56 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/pkg/conn"
+)
+
+func TestMergeTxStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		txst []byte
+		want byte
+	}{
+		{name: "idle", txst: []byte{conn.TXREL, conn.TXREL}, want: conn.TXREL},
+		{name: "in transaction on some datashard", txst: []byte{conn.TXREL, conn.NOTXREL}, want: conn.NOTXREL},
+		{name: "failed on some datashard", txst: []byte{conn.NOTXREL, conn.TXERR, conn.TXREL}, want: conn.TXERR},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeTxStatus(tt.txst); got != tt.want {
+				t.Errorf("mergeTxStatus(%q) = %q, want %q", tt.txst, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompatibleRows(t *testing.T) {
+	desc := func(fields ...pgproto3.FieldDescription) *pgproto3.RowDescription {
+		return &pgproto3.RowDescription{Fields: fields}
+	}
+
+	// int8 and text
+	id := pgproto3.FieldDescription{Name: []byte("id"), DataTypeOID: 20}
+	name := pgproto3.FieldDescription{Name: []byte("name"), DataTypeOID: 25}
+	binaryID := pgproto3.FieldDescription{Name: []byte("id"), DataTypeOID: 20, Format: 1}
+	renamedID := pgproto3.FieldDescription{Name: []byte("key"), DataTypeOID: 20}
+
+	for _, tt := range []struct {
+		name     string
+		lhs, rhs *pgproto3.RowDescription
+		want     bool
+	}{
+		{name: "same columns", lhs: desc(id, name), rhs: desc(id, name), want: true},
+		{name: "column names are not compared", lhs: desc(id), rhs: desc(renamedID), want: true},
+		{name: "different number of columns", lhs: desc(id, name), rhs: desc(id)},
+		{name: "different types", lhs: desc(id, name), rhs: desc(name, id)},
+		{name: "different formats", lhs: desc(id), rhs: desc(binaryID)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compatibleRows(tt.lhs, tt.rhs); got != tt.want {
+				t.Errorf("compatibleRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,8 @@ package server
 import (
 	"crypto/tls"
 	"fmt"
-	"reflect"
-	"sync"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgproto3/v2"
 	"github.com/pg-sharding/spqr/pkg/asynctracelog"
@@ -21,10 +21,19 @@ type MultiShardServer struct {
 	activeShards []datashard.Shard
 
 	pool conn.ConnPool
+
+	// replies of datashards up to next synchronization point
+	phase *replyPhase
+	// replies, composed in advance
+	pending []pgproto3.BackendMessage
+
+	// merge plan of next result and merger of its rows
+	plan   *MergePlan
+	merger *rowMerger
 }
 
 func (m *MultiShardServer) Reset() error {
-	panic("implement me")
+	return nil
 }
 
 func (m *MultiShardServer) AddShard(shkey kr.ShardKey) error {
@@ -75,95 +84,125 @@ func (m *MultiShardServer) Send(msg pgproto3.FrontendMessage) error {
 	return nil
 }
 
-func (m *MultiShardServer) Receive() (pgproto3.BackendMessage, error) {
-	ch := make(chan pgproto3.BackendMessage, len(m.activeShards))
-	errch := make(chan error, 0)
-	defer close(errch)
+func (m *MultiShardServer) SendShard(shkey kr.ShardKey, msg pgproto3.FrontendMessage) error {
+	for _, shard := range m.activeShards {
+		if shard.Name() != shkey.Name {
+			continue
+		}
 
-	wg := sync.WaitGroup{}
+		asynctracelog.Printf("sending Q to sh %v", shard.Name())
+		return shard.Send(msg)
+	}
 
-	wg.Add(len(m.activeShards))
-	for _, currshard := range m.activeShards {
-		asynctracelog.Printf("recv mult resp from sh %s", currshard.Name())
+	return xerrors.Errorf("datashard %v does not match any of active", shkey.Name)
+}
 
-		currshard := currshard
-		go func() {
-			err := func(shard datashard.Shard, ch chan<- pgproto3.BackendMessage, wg *sync.WaitGroup) error {
-				defer wg.Done()
+func (m *MultiShardServer) ReceiveShard(shkey kr.ShardKey) (pgproto3.BackendMessage, error) {
+	for _, shard := range m.activeShards {
+		if shard.Name() != shkey.Name {
+			continue
+		}
 
-				msg, err := shard.Receive()
-				if err != nil {
-					return err
-				}
-				asynctracelog.Printf("got %v from %s", msg, shard.Name())
+		return shard.Receive()
+	}
 
-				ch <- msg
+	return nil, xerrors.Errorf("datashard %v does not match any of active", shkey.Name)
+}
 
-				return nil
-			}(currshard, ch, &wg)
+// Receive returns merged replies of datashards. Data rows are relayed as they
+// arrive from any datashard, unless they are merged by merge plan. Other replies
+// are synchronization points: they are merged into one, when every datashard replies.
+func (m *MultiShardServer) Receive() (pgproto3.BackendMessage, error) {
+	if len(m.pending) > 0 {
+		var msg pgproto3.BackendMessage
+		msg, m.pending = m.pending[0], m.pending[1:]
+		return msg, nil
+	}
 
-			if err != nil {
-				errch <- err
-			}
-		}()
+	if m.phase == nil {
+		m.startPhase()
 	}
 
-	wg.Wait()
-	close(ch)
-
-	msgs := make([]pgproto3.BackendMessage, 0, len(m.activeShards))
-
-	err := func() error {
-		for {
-			select {
-			case msg, ok := <-ch:
-				if !ok {
-					// all shards messages are collected
-					return nil
-				}
-				msgs = append(msgs, msg)
-			case err := <-errch:
-				return err
+	for {
+		if m.merger != nil {
+			if row, ok := m.merger.next(m.exhausted); ok {
+				return row, nil
 			}
 		}
-	}()
 
-	if err != nil {
-		return nil, err
-	}
+		if m.phase.synced == len(m.activeShards) {
+			syncMsgs := m.phase.syncMsgs
+			m.stopPhase()
 
-	for i := range msgs {
-		if reflect.TypeOf(msgs[0]) != reflect.TypeOf(msgs[i]) {
-			return nil, xerrors.Errorf("got messages with different types from multiconnection %T, %T", msgs[0], msgs[i])
+			return m.merge(syncMsgs)
+		}
+
+		smsg := <-m.phase.ch
+		if smsg.err != nil {
+			m.stopPhase()
+			m.resetMerge()
+			return nil, smsg.err
 		}
-	}
 
-	tracelog.InfoLogger.Printf("compute multi server msgs from %T", msgs[0])
-
-	switch v := msgs[0].(type) {
-	case *pgproto3.CommandComplete:
-		return v, nil
-	case *pgproto3.ErrorResponse:
-		return v, nil
-	case *pgproto3.ReadyForQuery:
-		return v, nil
-	case *pgproto3.DataRow:
-		ret := &pgproto3.DataRow{}
-
-		for i, msg := range msgs {
-			if i == 0 {
-				ret = msg.(*pgproto3.DataRow)
+		if !smsg.sync {
+			if row, ok := smsg.msg.(*pgproto3.DataRow); ok && m.merger != nil {
+				m.merger.push(smsg.idx, row)
 				continue
 			}
-			drow := msg.(*pgproto3.DataRow)
-			ret.Values = append(ret.Values, drow.Values...)
+
+			return smsg.msg, nil
+		}
+
+		asynctracelog.Printf("got %T from %s", smsg.msg, m.activeShards[smsg.idx].Name())
+
+		m.phase.syncMsgs[smsg.idx] = smsg.msg
+		m.phase.synced++
+	}
+}
+
+// SetMergePlan sets merge plan of next result rows
+func (m *MultiShardServer) SetMergePlan(plan *MergePlan) {
+	m.plan = plan
+	m.merger = nil
+}
+
+// exhausted checks if datashard has sent all rows of current result
+func (m *MultiShardServer) exhausted(idx int) bool {
+	return m.phase.syncMsgs[idx] != nil
+}
+
+func (m *MultiShardServer) resetMerge() {
+	m.plan = nil
+	m.merger = nil
+}
+
+// MergeCommandTags sums row counts of shards command tags, e.g.
+// INSERT 0 2 and INSERT 0 3 are merged to INSERT 0 5
+func MergeCommandTags(tags [][]byte) []byte {
+	var cmd []string
+	var rows uint64
+
+	for _, tag := range tags {
+		fields := strings.Fields(string(tag))
+		if len(fields) < 2 {
+			return tags[0]
 		}
 
-		return ret, nil
+		cnt, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+		if err != nil {
+			return tags[0]
+		}
 
-	default:
-		return &pgproto3.ErrorResponse{Severity: "ERROR", Message: fmt.Sprintf("failed to conpose responce %T", v)}, nil
+		if cmd == nil {
+			cmd = fields[:len(fields)-1]
+		} else if strings.Join(cmd, " ") != strings.Join(fields[:len(fields)-1], " ") {
+			return tags[0]
+		}
+
+		rows += cnt
 	}
+
+	return []byte(fmt.Sprintf("%s %d", strings.Join(cmd, " "), rows))
 }
 
 func (m *MultiShardServer) Cleanup() error {
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgproto3/v2"
+	"github.com/pg-sharding/spqr/router/pkg/datashard"
+	"golang.org/x/xerrors"
+)
+
+// fakeShard replies with prepared messages
+type fakeShard struct {
+	datashard.Shard
+
+	name    string
+	replies []pgproto3.BackendMessage
+}
+
+func (sh *fakeShard) Name() string {
+	return sh.name
+}
+
+func (sh *fakeShard) Receive() (pgproto3.BackendMessage, error) {
+	if len(sh.replies) == 0 {
+		return nil, xerrors.New("no more replies")
+	}
+
+	var msg pgproto3.BackendMessage
+	msg, sh.replies = sh.replies[0], sh.replies[1:]
+	return msg, nil
+}
+
+// extendedReplies are replies of datashard to Bind, Describe and Execute of portal
+func extendedReplies(desc *pgproto3.RowDescription, rows ...*pgproto3.DataRow) []pgproto3.BackendMessage {
+	replies := []pgproto3.BackendMessage{&pgproto3.BindComplete{}, desc}
+	for _, row := range rows {
+		replies = append(replies, row)
+	}
+	return append(replies,
+		&pgproto3.CommandComplete{CommandTag: []byte(fmt.Sprintf("SELECT %d", len(rows)))},
+		&pgproto3.ReadyForQuery{TxStatus: 'I'})
+}
+
+// receiveReplies returns merged replies of datashards up to ReadyForQuery
+func receiveReplies(t *testing.T, plan *MergePlan, shards ...[]pgproto3.BackendMessage) []string {
+	t.Helper()
+
+	m := &MultiShardServer{}
+	for i, replies := range shards {
+		m.activeShards = append(m.activeShards, &fakeShard{name: fmt.Sprintf("sh%d", i+1), replies: replies})
+	}
+	m.SetMergePlan(plan)
+
+	var ret []string
+	for {
+		msg, err := m.Receive()
+		if err != nil {
+			t.Fatalf("Receive: %v", err)
+		}
+
+		switch v := msg.(type) {
+		case *pgproto3.DataRow:
+			ret = append(ret, strings.Join(rowValues(v), ","))
+		case *pgproto3.CommandComplete:
+			ret = append(ret, string(v.CommandTag))
+		case *pgproto3.ErrorResponse:
+			ret = append(ret, v.Message)
+		default:
+			ret = append(ret, reflect.TypeOf(msg).Elem().Name())
+		}
+
+		if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+			return ret
+		}
+	}
+}
+
+func TestMergeCommandTags(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{name: "insert", tags: []string{"INSERT 0 2", "INSERT 0 3"}, want: "INSERT 0 5"},
+		{name: "update", tags: []string{"UPDATE 1", "UPDATE 0", "UPDATE 4"}, want: "UPDATE 5"},
+		{name: "select", tags: []string{"SELECT 10", "SELECT 20"}, want: "SELECT 30"},
+		{name: "single datashard", tags: []string{"DELETE 7"}, want: "DELETE 7"},
+		{name: "tag without row count", tags: []string{"BEGIN", "BEGIN"}, want: "BEGIN"},
+		{name: "different commands", tags: []string{"INSERT 0 1", "UPDATE 1"}, want: "INSERT 0 1"},
+		{name: "non-numeric row count", tags: []string{"CREATE TABLE", "CREATE TABLE"}, want: "CREATE TABLE"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags [][]byte
+			for _, tag := range tt.tags {
+				tags = append(tags, []byte(tag))
+			}
+
+			if got := string(MergeCommandTags(tags)); got != tt.want {
+				t.Errorf("MergeCommandTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiveMergedRows(t *testing.T) {
+	desc := &pgproto3.RowDescription{Fields: []pgproto3.FieldDescription{
+		{Name: []byte("id"), DataTypeOID: int8OID},
+	}}
+	shards := func() [][]pgproto3.BackendMessage {
+		return [][]pgproto3.BackendMessage{
+			extendedReplies(desc, dataRow("1"), dataRow("4")),
+			extendedReplies(desc, dataRow("2"), dataRow("3")),
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		plan *MergePlan
+		want []string
+	}{
+		{
+			name: "described by client",
+			plan: &MergePlan{SortKeys: []SortKey{{Column: 0}}},
+			want: []string{"BindComplete", "RowDescription", "1", "2", "3", "4", "SELECT 4", "ReadyForQuery"},
+		},
+		{
+			name: "described by router",
+			plan: &MergePlan{SortKeys: []SortKey{{Column: 0}}, ImplicitDescribe: true},
+			want: []string{"BindComplete", "1", "2", "3", "4", "SELECT 4", "ReadyForQuery"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := receiveReplies(t, tt.plan, shards()...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replies %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
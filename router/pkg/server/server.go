@@ -9,7 +9,13 @@ import (
 
 type Server interface {
 	Send(query pgproto3.FrontendMessage) error
+	// SendShard sends message to one of active shards only
+	SendShard(shkey kr.ShardKey, query pgproto3.FrontendMessage) error
 	Receive() (pgproto3.BackendMessage, error)
+	// ReceiveShard receives message from one of active shards only
+	ReceiveShard(shkey kr.ShardKey) (pgproto3.BackendMessage, error)
+	// SetMergePlan sets merge plan of next result rows of active shards
+	SetMergePlan(plan *MergePlan)
 
 	AddShard(shkey kr.ShardKey) error
 	UnrouteShard(sh kr.ShardKey) error
@@ -74,10 +74,29 @@ func (srv *ShardServer) Send(query pgproto3.FrontendMessage) error {
 	return srv.shard.Send(query)
 }
 
+func (srv *ShardServer) SendShard(shkey kr.ShardKey, query pgproto3.FrontendMessage) error {
+	if srv.shard.SHKey().Name != shkey.Name {
+		return xerrors.Errorf("active datashard does not match: %v != %v", srv.shard.SHKey().Name, shkey.Name)
+	}
+
+	return srv.shard.Send(query)
+}
+
 func (srv *ShardServer) Receive() (pgproto3.BackendMessage, error) {
 	return srv.shard.Receive()
 }
 
+func (srv *ShardServer) ReceiveShard(shkey kr.ShardKey) (pgproto3.BackendMessage, error) {
+	if srv.shard.SHKey().Name != shkey.Name {
+		return nil, xerrors.Errorf("active datashard does not match: %v != %v", srv.shard.SHKey().Name, shkey.Name)
+	}
+
+	return srv.shard.Receive()
+}
+
+// SetMergePlan is no-op: single datashard result needs no merge
+func (srv *ShardServer) SetMergePlan(plan *MergePlan) {}
+
 func (srv *ShardServer) Cleanup() error {
 
 	if srv.rule.PoolRollback {
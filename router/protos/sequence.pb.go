@@ -0,0 +1,308 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.6.1
+// source: protos/sequence.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// block of sequence values [first, first + count)
+type SequenceRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	First int64  `protobuf:"varint,2,opt,name=first,proto3" json:"first,omitempty"`
+	Count int64  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *SequenceRange) Reset() {
+	*x = SequenceRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_sequence_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SequenceRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SequenceRange) ProtoMessage() {}
+
+func (x *SequenceRange) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_sequence_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SequenceRange.ProtoReflect.Descriptor instead.
+func (*SequenceRange) Descriptor() ([]byte, []int) {
+	return file_protos_sequence_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SequenceRange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SequenceRange) GetFirst() int64 {
+	if x != nil {
+		return x.First
+	}
+	return 0
+}
+
+func (x *SequenceRange) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type NextRangeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Count int64  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *NextRangeRequest) Reset() {
+	*x = NextRangeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_sequence_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NextRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextRangeRequest) ProtoMessage() {}
+
+func (x *NextRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_sequence_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextRangeRequest.ProtoReflect.Descriptor instead.
+func (*NextRangeRequest) Descriptor() ([]byte, []int) {
+	return file_protos_sequence_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NextRangeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NextRangeRequest) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type NextRangeReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Range *SequenceRange `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+}
+
+func (x *NextRangeReply) Reset() {
+	*x = NextRangeReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_sequence_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NextRangeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NextRangeReply) ProtoMessage() {}
+
+func (x *NextRangeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_sequence_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NextRangeReply.ProtoReflect.Descriptor instead.
+func (*NextRangeReply) Descriptor() ([]byte, []int) {
+	return file_protos_sequence_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NextRangeReply) GetRange() *SequenceRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+var File_protos_sequence_proto protoreflect.FileDescriptor
+
+var file_protos_sequence_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x79, 0x61, 0x6e, 0x64, 0x65, 0x78, 0x2e,
+	0x73, 0x70, 0x71, 0x72, 0x22, 0x4f, 0x0a, 0x0d, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65,
+	0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x72,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x66, 0x69, 0x72, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x3c, 0x0a, 0x10, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x22, 0x42, 0x0a, 0x0e, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x30, 0x0a, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x79, 0x61, 0x6e, 0x64, 0x65, 0x78, 0x2e, 0x73, 0x70,
+	0x71, 0x72, 0x2e, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x61, 0x6e, 0x67, 0x65,
+	0x52, 0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x32, 0x5c, 0x0a, 0x0f, 0x53, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x49, 0x0a, 0x09, 0x4e, 0x65,
+	0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1d, 0x2e, 0x79, 0x61, 0x6e, 0x64, 0x65, 0x78,
+	0x2e, 0x73, 0x70, 0x71, 0x72, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x79, 0x61, 0x6e, 0x64, 0x65, 0x78, 0x2e,
+	0x73, 0x70, 0x71, 0x72, 0x2e, 0x4e, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x13, 0x5a, 0x11, 0x79, 0x61, 0x6e, 0x64, 0x65, 0x78, 0x2f,
+	0x73, 0x70, 0x71, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_protos_sequence_proto_rawDescOnce sync.Once
+	file_protos_sequence_proto_rawDescData = file_protos_sequence_proto_rawDesc
+)
+
+func file_protos_sequence_proto_rawDescGZIP() []byte {
+	file_protos_sequence_proto_rawDescOnce.Do(func() {
+		file_protos_sequence_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_sequence_proto_rawDescData)
+	})
+	return file_protos_sequence_proto_rawDescData
+}
+
+var file_protos_sequence_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_protos_sequence_proto_goTypes = []interface{}{
+	(*SequenceRange)(nil),    // 0: yandex.spqr.SequenceRange
+	(*NextRangeRequest)(nil), // 1: yandex.spqr.NextRangeRequest
+	(*NextRangeReply)(nil),   // 2: yandex.spqr.NextRangeReply
+}
+var file_protos_sequence_proto_depIdxs = []int32{
+	0, // 0: yandex.spqr.NextRangeReply.range:type_name -> yandex.spqr.SequenceRange
+	1, // 1: yandex.spqr.SequenceService.NextRange:input_type -> yandex.spqr.NextRangeRequest
+	2, // 2: yandex.spqr.SequenceService.NextRange:output_type -> yandex.spqr.NextRangeReply
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_protos_sequence_proto_init() }
+func file_protos_sequence_proto_init() {
+	if File_protos_sequence_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_protos_sequence_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SequenceRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_sequence_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NextRangeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_sequence_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NextRangeReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_protos_sequence_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_protos_sequence_proto_goTypes,
+		DependencyIndexes: file_protos_sequence_proto_depIdxs,
+		MessageInfos:      file_protos_sequence_proto_msgTypes,
+	}.Build()
+	File_protos_sequence_proto = out.File
+	file_protos_sequence_proto_rawDesc = nil
+	file_protos_sequence_proto_goTypes = nil
+	file_protos_sequence_proto_depIdxs = nil
+}
@@ -0,0 +1,101 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SequenceServiceClient is the client API for SequenceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SequenceServiceClient interface {
+	NextRange(ctx context.Context, in *NextRangeRequest, opts ...grpc.CallOption) (*NextRangeReply, error)
+}
+
+type sequenceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSequenceServiceClient(cc grpc.ClientConnInterface) SequenceServiceClient {
+	return &sequenceServiceClient{cc}
+}
+
+func (c *sequenceServiceClient) NextRange(ctx context.Context, in *NextRangeRequest, opts ...grpc.CallOption) (*NextRangeReply, error) {
+	out := new(NextRangeReply)
+	err := c.cc.Invoke(ctx, "/yandex.spqr.SequenceService/NextRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SequenceServiceServer is the server API for SequenceService service.
+// All implementations must embed UnimplementedSequenceServiceServer
+// for forward compatibility
+type SequenceServiceServer interface {
+	NextRange(context.Context, *NextRangeRequest) (*NextRangeReply, error)
+	mustEmbedUnimplementedSequenceServiceServer()
+}
+
+// UnimplementedSequenceServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSequenceServiceServer struct {
+}
+
+func (UnimplementedSequenceServiceServer) NextRange(context.Context, *NextRangeRequest) (*NextRangeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NextRange not implemented")
+}
+func (UnimplementedSequenceServiceServer) mustEmbedUnimplementedSequenceServiceServer() {}
+
+// UnsafeSequenceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SequenceServiceServer will
+// result in compilation errors.
+type UnsafeSequenceServiceServer interface {
+	mustEmbedUnimplementedSequenceServiceServer()
+}
+
+func RegisterSequenceServiceServer(s grpc.ServiceRegistrar, srv SequenceServiceServer) {
+	s.RegisterService(&SequenceService_ServiceDesc, srv)
+}
+
+func _SequenceService_NextRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NextRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SequenceServiceServer).NextRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/yandex.spqr.SequenceService/NextRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SequenceServiceServer).NextRange(ctx, req.(*NextRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SequenceService_ServiceDesc is the grpc.ServiceDesc for SequenceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SequenceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "yandex.spqr.SequenceService",
+	HandlerType: (*SequenceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NextRange",
+			Handler:    _SequenceService_NextRange_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/sequence.proto",
+}
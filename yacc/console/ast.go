@@ -3,6 +3,7 @@ package spqrparser
 import (
 	"errors"
 	"strings"
+	"unicode"
 )
 
 type Show struct {
@@ -13,11 +14,19 @@ type ShardingColumn struct {
 	ColName string
 }
 
+type ShardingRule struct {
+	ID           string
+	TableName    string
+	Columns      []string
+	HashFunction string
+}
+
 type AddKeyRange struct {
 	LowerBound []byte
 	UpperBound []byte
 	ShardID    string
 	KeyRangeID string
+	KeyType    string
 }
 
 type SplitKeyRange struct {
@@ -65,6 +74,11 @@ type Kill struct {
 	Cmd string
 }
 
+// Explain routes query without executing it
+type Explain struct {
+	Query string
+}
+
 // coordinator
 
 type RegisterRouter struct {
@@ -81,9 +95,11 @@ const (
 	ShowDatabasesStr    = "databases"
 	ShowShardsStr       = "shards"
 	ShowShardingColumns = "sharding_columns"
+	ShowShardingRules   = "sharding_rules"
 	ShowKeyRangesStr    = "key_ranges"
 	KillClientsStr      = "clients"
 	ShowPoolsStr        = "pools"
+	ShowHostsStr        = "hosts"
 	ShowUnsupportedStr  = "unsupported"
 )
 
@@ -103,9 +119,11 @@ func (*MoveKeyRange) iStatement()   {}
 func (*SplitKeyRange) iStatement()  {}
 func (*UniteKeyRange) iStatement()  {}
 func (*ShardingColumn) iStatement() {}
+func (*ShardingRule) iStatement()   {}
 func (*AddKeyRange) iStatement()    {}
 func (*Shard) iStatement()          {}
 func (*Kill) iStatement()           {}
+func (*Explain) iStatement()        {}
 
 func (*RegisterRouter) iStatement()   {}
 func (*UnregisterRouter) iStatement() {}
@@ -125,6 +143,7 @@ var reservedWords = map[string]int{
 	"key":        KEY,
 	"range":      RANGE,
 	"shards":     SHARDS,
+	"hosts":      HOSTS,
 	"key_ranges": KEY_RANGES,
 	"lock":       LOCK,
 	"unlock":     UNLOCK,
@@ -139,6 +158,16 @@ var reservedWords = map[string]int{
 	"register":   REGISTER,
 	"unregister": UNREGISTER,
 	"router":     ROUTER,
+	"rule":       RULE,
+	"columns":    COLUMNS,
+	"table":      TABLE,
+	"hash":       HASH,
+	"function":   FUNCTION,
+	"type":       TYPE,
+	"explain":    EXPLAIN,
+	"route":      ROUTE,
+
+	"sharding_rules": SHARDING_RULES,
 }
 
 // Tokenizer is the struct used to generate SQL
@@ -147,39 +176,64 @@ type Tokenizer struct {
 	s   string
 	pos int
 
+	// last returned token
+	prev int
+
 	ParseTree Statement
 	LastError string
 }
 
 func (t *Tokenizer) Lex(lval *yySymType) int {
-	var c rune = ' '
+	// skip through all the spaces
+	for t.pos < len(t.s) && unicode.IsSpace(rune(t.s[t.pos])) {
+		t.pos += 1
+	}
+
+	if t.pos == len(t.s) {
+		return 0
+	}
 
-	// skip through all the spaces, both at the ends and in between
-	for c == ' ' {
-		if t.pos == len(t.s) {
+	// query of EXPLAIN ROUTE is a single token
+	if t.prev == ROUTE {
+		tok := strings.TrimRightFunc(t.s[t.pos:], func(r rune) bool {
+			return r == ';' || unicode.IsSpace(r)
+		})
+		t.pos = len(t.s)
+		if tok == "" {
 			return 0
 		}
-		c = rune(t.s[t.pos])
-		t.pos += 1
+
+		t.prev = STRING
+		lval.str = tok
+		return STRING
 	}
 
-	tok := ""
+	// separators are tokens on their own
+	switch c := t.s[t.pos]; c {
+	case ',', ';':
+		t.pos += 1
+		t.prev = int(c)
+		lval.str = string(c)
+		return int(c)
+	}
 
-	// skip through all the spaces, both at the ends and in between
-	for c != ' ' {
-		if t.pos == len(t.s) {
-			break
-		}
-		tok = tok + string(c)
-		c = rune(t.s[t.pos])
+	start := t.pos
+	for t.pos < len(t.s) && !unicode.IsSpace(rune(t.s[t.pos])) && t.s[t.pos] != ',' && t.s[t.pos] != ';' {
 		t.pos += 1
 	}
+
+	tok := t.s[start:t.pos]
 	lval.str = tok
 
 	if tp, ok := reservedWords[strings.ToLower(tok)]; ok {
+		if tp == ROUTE && t.prev != EXPLAIN {
+			tp = STRING
+		}
+		t.prev = tp
 		return tp
 	}
 
+	t.prev = STRING
 	return STRING
 }
 
@@ -0,0 +1,107 @@
+package spqrparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		query string
+		want  Statement
+	}{
+		{
+			query: "SHOW key_ranges",
+			want:  &Show{Cmd: ShowKeyRangesStr},
+		},
+		{
+			query: "show sharding_rules;",
+			want:  &Show{Cmd: ShowShardingRules},
+		},
+		{
+			query: "SHOW stats",
+			want:  &Show{Cmd: ShowUnsupportedStr},
+		},
+		{
+			query: "KILL clients",
+			want:  &Kill{Cmd: KillClientsStr},
+		},
+		{
+			query: "ADD KEY RANGE 1 10 sh1 krid1",
+			want:  &AddKeyRange{LowerBound: []byte("1"), UpperBound: []byte("10"), ShardID: "sh1", KeyRangeID: "krid1", KeyType: ""},
+		},
+		{
+			query: "ADD KEY RANGE 1,a 10,b sh1 krid1 TYPE varchar",
+			want:  &AddKeyRange{LowerBound: []byte("1,a"), UpperBound: []byte("10,b"), ShardID: "sh1", KeyRangeID: "krid1", KeyType: "varchar"},
+		},
+		{
+			query: "ADD SHARDING RULE r1 COLUMNS id",
+			want:  &ShardingRule{ID: "r1", Columns: []string{"id"}},
+		},
+		{
+			query: "ADD SHARDING RULE r1 TABLE t COLUMNS a, b HASH FUNCTION murmur3",
+			want:  &ShardingRule{ID: "r1", TableName: "t", Columns: []string{"a", "b"}, HashFunction: "murmur3"},
+		},
+		{
+			query: "CREATE SHARDING COLUMN id",
+			want:  &ShardingColumn{ColName: "id"},
+		},
+		{
+			query: "DROP KEY RANGE krid1",
+			want:  &Drop{KeyRangeID: "krid1"},
+		},
+		{
+			query: "LOCK KEY RANGE krid1",
+			want:  &Lock{KeyRangeID: "krid1"},
+		},
+		{
+			query: "UNLOCK KEY RANGE krid1",
+			want:  &Unlock{KeyRangeID: "krid1"},
+		},
+		{
+			query: "SPLIT KEY RANGE krid2 FROM krid1 BY 5",
+			want:  &SplitKeyRange{KeyRangeID: "krid2", KeyRangeFromID: "krid1", Border: []byte("5")},
+		},
+		{
+			query: "UNITE KEY RANGE krid1 WITH krid2",
+			want:  &UniteKeyRange{KeyRangeIDL: "krid1", KeyRangeIDR: "krid2"},
+		},
+		{
+			query: "EXPLAIN ROUTE SELECT * FROM t WHERE id IN (1, 2);",
+			want:  &Explain{Query: "SELECT * FROM t WHERE id IN (1, 2)"},
+		},
+		{
+			query: "REGISTER ROUTER localhost:7000 r1",
+			want:  &RegisterRouter{Addr: "localhost:7000", ID: "r1"},
+		},
+		{
+			query: "UNREGISTER ROUTER r1",
+			want:  &UnregisterRouter{ID: "r1"},
+		},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(stmt, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.query, stmt, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"SHOW",
+		"ADD KEY RANGE 1 10 sh1",
+		"ADD SHARDING RULE r1 TABLE t",
+		"EXPLAIN ROUTE",
+		"DROP KEY RANGE",
+		"SELECT 1",
+	} {
+		if stmt, err := Parse(query); err == nil {
+			t.Errorf("Parse(%q) = %#v, want error", query, stmt)
+		}
+	}
+}
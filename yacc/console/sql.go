@@ -1,14 +1,14 @@
-// Code generated by goyacc -o yacc/console/sql.go -p yy yacc/console/sql.y. DO NOT EDIT.
+// Code generated by goyacc -o sql.go -p yy sql.y. DO NOT EDIT.
 
-//line yacc/console/sql.y:3
+//line sql.y:3
 
 package spqrparser
 
 import __yyfmt__ "fmt"
 
-//line yacc/console/sql.y:4
+//line sql.y:4
 
-//line yacc/console/sql.y:11
+//line sql.y:11
 type yySymType struct {
 	yys               int
 	empty             struct{}
@@ -16,9 +16,11 @@ type yySymType struct {
 	show              *Show
 	kr                *AddKeyRange
 	sh_col            *ShardingColumn
+	shrule            *ShardingRule
 	register_router   *RegisterRouter
 	unregister_router *UnregisterRouter
 	kill              *Kill
+	explain           *Explain
 	drop              *Drop
 	lock              *Lock
 	shutdown          *Shutdown
@@ -30,6 +32,7 @@ type yySymType struct {
 	str               string
 	byte              byte
 	bytes             []byte
+	strlist           []string
 	int               int
 	bool              bool
 }
@@ -44,29 +47,39 @@ const LISTS = 57352
 const SERVERS = 57353
 const CLIENTS = 57354
 const DATABASES = 57355
-const SHUTDOWN = 57356
-const LISTEN = 57357
-const REGISTER = 57358
-const UNREGISTER = 57359
-const ROUTER = 57360
-const CREATE = 57361
-const ADD = 57362
-const DROP = 57363
-const LOCK = 57364
-const UNLOCK = 57365
-const SPLIT = 57366
-const MOVE = 57367
-const SHARDING = 57368
-const COLUMN = 57369
-const KEY = 57370
-const RANGE = 57371
-const SHARDS = 57372
-const KEY_RANGES = 57373
-const BY = 57374
-const FROM = 57375
-const TO = 57376
-const WITH = 57377
-const UNITE = 57378
+const HOSTS = 57356
+const SHUTDOWN = 57357
+const LISTEN = 57358
+const REGISTER = 57359
+const UNREGISTER = 57360
+const ROUTER = 57361
+const CREATE = 57362
+const ADD = 57363
+const DROP = 57364
+const LOCK = 57365
+const UNLOCK = 57366
+const SPLIT = 57367
+const MOVE = 57368
+const SHARDING = 57369
+const COLUMN = 57370
+const KEY = 57371
+const RANGE = 57372
+const SHARDS = 57373
+const KEY_RANGES = 57374
+const RULE = 57375
+const COLUMNS = 57376
+const TABLE = 57377
+const HASH = 57378
+const FUNCTION = 57379
+const TYPE = 57380
+const SHARDING_RULES = 57381
+const BY = 57382
+const FROM = 57383
+const TO = 57384
+const WITH = 57385
+const UNITE = 57386
+const EXPLAIN = 57387
+const ROUTE = 57388
 
 var yyToknames = [...]string{
 	"$end",
@@ -82,6 +95,7 @@ var yyToknames = [...]string{
 	"SERVERS",
 	"CLIENTS",
 	"DATABASES",
+	"HOSTS",
 	"SHUTDOWN",
 	"LISTEN",
 	"REGISTER",
@@ -100,12 +114,22 @@ var yyToknames = [...]string{
 	"RANGE",
 	"SHARDS",
 	"KEY_RANGES",
+	"RULE",
+	"COLUMNS",
+	"TABLE",
+	"HASH",
+	"FUNCTION",
+	"TYPE",
+	"SHARDING_RULES",
 	"BY",
 	"FROM",
 	"TO",
 	"WITH",
 	"UNITE",
+	"EXPLAIN",
+	"ROUTE",
 	"';'",
+	"','",
 }
 
 var yyStatenames = [...]string{}
@@ -114,7 +138,7 @@ const yyEofCode = 1
 const yyErrCode = 2
 const yyInitialStackSize = 16
 
-//line yacc/console/sql.y:336
+//line sql.y:425
 
 //line yacctab:1
 var yyExca = [...]int{
@@ -125,83 +149,97 @@ var yyExca = [...]int{
 
 const yyPrivate = 57344
 
-const yyLast = 96
+const yyLast = 127
 
 var yyAct = [...]int{
-	74, 79, 89, 66, 22, 23, 36, 86, 85, 84,
-	93, 71, 25, 24, 29, 30, 70, 17, 31, 32,
-	33, 34, 26, 27, 40, 45, 69, 43, 42, 41,
-	68, 63, 62, 61, 28, 59, 58, 57, 56, 53,
-	52, 51, 60, 37, 55, 39, 44, 46, 54, 80,
-	75, 90, 67, 73, 65, 50, 35, 1, 64, 49,
-	72, 16, 15, 76, 77, 14, 13, 12, 78, 48,
-	81, 82, 83, 10, 11, 20, 6, 21, 7, 19,
-	5, 87, 18, 4, 3, 88, 9, 91, 8, 47,
-	92, 38, 2, 94, 0, 95,
+	83, 81, 90, 103, 73, 24, 25, 104, 91, 100,
+	39, 61, 97, 96, 27, 26, 31, 32, 117, 18,
+	34, 35, 36, 37, 28, 29, 95, 110, 119, 121,
+	115, 43, 48, 99, 46, 45, 44, 51, 80, 106,
+	76, 79, 78, 30, 33, 77, 70, 69, 68, 66,
+	65, 100, 100, 64, 47, 49, 62, 58, 63, 57,
+	56, 67, 50, 40, 42, 60, 59, 123, 122, 82,
+	85, 86, 91, 84, 108, 107, 87, 104, 89, 92,
+	93, 94, 74, 75, 72, 55, 38, 1, 71, 118,
+	53, 54, 16, 101, 15, 14, 102, 13, 105, 12,
+	10, 11, 22, 6, 23, 109, 7, 21, 112, 5,
+	113, 4, 19, 114, 116, 98, 88, 120, 111, 20,
+	3, 17, 9, 8, 52, 41, 2,
 }
 
 var yyPact = [...]int{
-	-2, -1000, -31, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, 17, -1000, -1000,
-	-1000, -1000, 16, 16, 51, -1000, 13, 12, 11, 30,
-	26, 10, 9, 8, 7, -1000, -1000, 15, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 4, 3, 2, 50, 48, 1, -3, -13, -18,
-	49, 46, 46, 46, 48, -1000, -1000, -1000, 45, 46,
-	46, 46, -1000, -1000, -24, -1000, -26, -28, -1000, 45,
-	-1000, -1000, -1000, -1000, 46, 47, 46, 47, -22, -1000,
-	-1000, -1000, 46, 45, -1000, -1000,
+	-1, -1000, -37, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 36, -1000,
+	-1000, -1000, -1000, -1000, 23, 23, 81, -1000, 31, 30,
+	28, 47, 46, -35, 29, 24, 21, 20, -1000, -1000,
+	33, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 18, 17, 16, 80,
+	78, 79, 7, 15, 12, 11, 8, 65, 69, 69,
+	69, 78, -1000, -1000, -1000, -1000, 74, 68, 69, 69,
+	69, -1000, -1000, -15, -1000, -29, -31, -1000, -2, -1000,
+	4, -1000, -1000, -1000, -1000, 69, 73, 69, 5, 71,
+	70, 3, -13, -1000, -1000, -1000, 65, -1000, -1000, 69,
+	68, -18, -1000, -10, -39, 65, -1000, -8, -1000, 64,
+	-1000, 63, -1000, -1000,
 }
 
 var yyPgo = [...]int{
-	0, 92, 91, 89, 88, 86, 84, 83, 82, 80,
-	79, 78, 77, 76, 75, 74, 73, 67, 66, 65,
-	62, 61, 45, 60, 2, 59, 1, 0, 3, 58,
-	57, 56,
+	0, 126, 125, 124, 123, 122, 121, 120, 119, 118,
+	116, 115, 114, 112, 111, 109, 107, 106, 104, 103,
+	102, 101, 100, 99, 97, 95, 94, 92, 64, 1,
+	3, 91, 2, 0, 89, 4, 88, 87, 86,
 }
 
 var yyR1 = [...]int{
-	0, 30, 31, 31, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 1, 22, 22,
-	22, 22, 22, 22, 22, 2, 3, 4, 23, 26,
-	6, 27, 24, 25, 9, 13, 7, 11, 8, 10,
-	14, 12, 17, 5, 18, 19, 16, 15, 29, 28,
-	20, 21,
+	0, 37, 38, 38, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 28,
+	28, 28, 28, 28, 28, 28, 28, 28, 2, 3,
+	4, 29, 32, 32, 10, 9, 9, 11, 11, 12,
+	12, 8, 7, 33, 30, 31, 15, 19, 14, 14,
+	17, 34, 34, 13, 16, 20, 18, 23, 5, 6,
+	24, 25, 22, 21, 36, 35, 26, 27,
 }
 
 var yyR2 = [...]int{
 	0, 2, 0, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 2, 1, 1,
-	4, 1, 1, 1, 1, 1, 1, 1, 7, 4,
-	4, 4, 8, 2, 6, 6, 2, 1, 1, 1,
-	4, 3,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	2, 1, 1, 3, 1, 1, 3, 0, 2, 0,
+	3, 8, 4, 1, 1, 1, 1, 1, 1, 1,
+	1, 0, 2, 8, 4, 4, 4, 8, 2, 3,
+	6, 6, 2, 1, 1, 1, 4, 3,
 }
 
 var yyChk = [...]int{
-	-1000, -30, -1, -6, -7, -9, -13, -11, -4, -5,
-	-16, -15, -17, -18, -19, -20, -21, 19, -8, -10,
-	-14, -12, 6, 7, 15, 14, 24, 25, 36, 16,
-	17, 20, 21, 22, 23, -31, 37, 26, -2, -22,
-	8, 13, 12, 11, 30, 9, 31, -3, -22, -25,
-	4, 28, 28, 28, 18, 18, 28, 28, 28, 28,
-	27, 29, 29, 29, -29, 4, -28, 4, 29, 29,
-	29, 29, -23, 4, -27, 4, -27, -27, -28, -26,
-	4, -27, -27, -27, 33, 34, 35, -26, -27, -24,
-	4, -27, -24, 32, -27, -26,
+	-1000, -37, -1, -7, -14, -15, -19, -17, -4, -5,
+	-22, -21, -23, -24, -25, -26, -27, -6, 20, -13,
+	-8, -16, -20, -18, 6, 7, 16, 15, 25, 26,
+	44, 17, 18, 45, 21, 22, 23, 24, -38, 47,
+	27, -2, -28, 8, 13, 12, 11, 31, 9, 32,
+	39, 14, -3, -28, -31, 4, 29, 29, 29, 19,
+	19, 46, 27, 29, 29, 29, 29, 28, 30, 30,
+	30, -36, 4, -35, 4, 4, 33, 30, 30, 30,
+	30, -29, 4, -33, 4, -33, -33, -35, -10, 4,
+	-32, 4, -33, -33, -33, 41, 42, 43, -11, 35,
+	48, -32, -33, -30, 4, -33, 34, 4, 4, -30,
+	40, -9, -29, -33, -32, 48, -12, 36, -34, 38,
+	-29, 37, 4, 4,
 }
 
 var yyDef = [...]int{
 	0, -2, 2, 4, 5, 6, 7, 8, 9, 10,
-	11, 12, 13, 14, 15, 16, 17, 0, 36, 34,
-	35, 37, 0, 0, 0, 47, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 1, 3, 0, 27, 25,
-	18, 19, 20, 21, 22, 23, 24, 43, 26, 46,
-	33, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 48, 51, 49, 0, 0,
-	0, 0, 30, 28, 0, 31, 0, 0, 50, 0,
-	29, 39, 40, 41, 0, 0, 0, 0, 0, 44,
-	32, 45, 0, 0, 38, 42,
+	11, 12, 13, 14, 15, 16, 17, 18, 0, 48,
+	49, 46, 47, 50, 0, 0, 0, 63, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 1, 3,
+	0, 30, 28, 19, 20, 21, 22, 23, 24, 25,
+	26, 27, 58, 29, 62, 45, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 64, 67, 65, 59, 0, 0, 0, 0,
+	0, 42, 31, 0, 43, 0, 0, 66, 37, 34,
+	0, 32, 54, 55, 56, 0, 0, 0, 0, 0,
+	0, 0, 0, 60, 44, 61, 0, 38, 33, 0,
+	0, 39, 35, 51, 57, 0, 41, 0, 53, 0,
+	36, 0, 52, 40,
 }
 
 var yyTok1 = [...]int{
@@ -209,15 +247,16 @@ var yyTok1 = [...]int{
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 37,
+	3, 3, 3, 3, 48, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 47,
 }
 
 var yyTok2 = [...]int{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
-	32, 33, 34, 35, 36,
+	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
+	42, 43, 44, 45, 46,
 }
 
 var yyTok3 = [...]int{
@@ -563,112 +602,118 @@ yydefault:
 
 	case 2:
 		yyDollar = yyS[yypt-0 : yypt+1]
-//line yacc/console/sql.y:97
+//line sql.y:111
 		{
 		}
 	case 3:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:98
+//line sql.y:112
 		{
 		}
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:103
+//line sql.y:117
 		{
 			setParseTree(yylex, yyDollar[1].sh_col)
 		}
 	case 5:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:107
+//line sql.y:121
 		{
-			setParseTree(yylex, yyDollar[1].kr)
+			setParseTree(yylex, yyDollar[1].statement)
 		}
 	case 6:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:111
+//line sql.y:125
 		{
 			setParseTree(yylex, yyDollar[1].drop)
 		}
 	case 7:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:115
+//line sql.y:129
 		{
 			setParseTree(yylex, yyDollar[1].lock)
 		}
 	case 8:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:119
+//line sql.y:133
 		{
 			setParseTree(yylex, yyDollar[1].unlock)
 		}
 	case 9:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:123
+//line sql.y:137
 		{
 			setParseTree(yylex, yyDollar[1].show)
 		}
 	case 10:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:127
+//line sql.y:141
 		{
 			setParseTree(yylex, yyDollar[1].kill)
 		}
 	case 11:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:131
+//line sql.y:145
 		{
 			setParseTree(yylex, yyDollar[1].listen)
 		}
 	case 12:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:135
+//line sql.y:149
 		{
 			setParseTree(yylex, yyDollar[1].shutdown)
 		}
 	case 13:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:139
+//line sql.y:153
 		{
 			setParseTree(yylex, yyDollar[1].split)
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:143
+//line sql.y:157
 		{
 			setParseTree(yylex, yyDollar[1].move)
 		}
 	case 15:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:147
+//line sql.y:161
 		{
 			setParseTree(yylex, yyDollar[1].unite)
 		}
 	case 16:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:151
+//line sql.y:165
 		{
 			setParseTree(yylex, yyDollar[1].register_router)
 		}
 	case 17:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:155
+//line sql.y:169
 		{
 			setParseTree(yylex, yyDollar[1].unregister_router)
 		}
-	case 25:
+	case 18:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:173
+		{
+			setParseTree(yylex, yyDollar[1].explain)
+		}
+	case 28:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:170
+//line sql.y:190
 		{
 			switch v := string(yyDollar[1].str); v {
-			case ShowDatabasesStr, ShowPoolsStr, ShowShardsStr, ShowKeyRangesStr, ShowShardingColumns:
+			case ShowDatabasesStr, ShowPoolsStr, ShowShardsStr, ShowKeyRangesStr, ShowShardingColumns, ShowShardingRules, ShowHostsStr:
 				yyVAL.str = v
 			default:
 				yyVAL.str = ShowUnsupportedStr
 			}
 		}
-	case 26:
+	case 29:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:181
+//line sql.y:201
 		{
 			switch v := string(yyDollar[1].str); v {
 			case KillClientsStr:
@@ -677,129 +722,213 @@ yydefault:
 				yyVAL.str = "unsupp"
 			}
 		}
-	case 27:
+	case 30:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line yacc/console/sql.y:193
+//line sql.y:213
 		{
 			yyVAL.show = &Show{Cmd: yyDollar[2].str}
 		}
-	case 28:
+	case 31:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:200
+//line sql.y:220
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 29:
+	case 32:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:206
+//line sql.y:226
 		{
 			yyVAL.bytes = []byte(yyDollar[1].str)
 		}
-	case 30:
+	case 33:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:230
+		{
+			yyVAL.bytes = append(append(yyDollar[1].bytes, ','), yyDollar[3].str...)
+		}
+	case 34:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:236
+		{
+			yyVAL.str = string(yyDollar[1].str)
+		}
+	case 35:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:242
+		{
+			yyVAL.strlist = []string{yyDollar[1].str}
+		}
+	case 36:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:246
+		{
+			yyVAL.strlist = append(yyDollar[1].strlist, yyDollar[3].str)
+		}
+	case 37:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:252
+		{
+			yyVAL.str = ""
+		}
+	case 38:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:256
+		{
+			yyVAL.str = string(yyDollar[2].str)
+		}
+	case 39:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:262
+		{
+			yyVAL.str = ""
+		}
+	case 40:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:266
+		{
+			yyVAL.str = string(yyDollar[3].str)
+		}
+	case 41:
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line sql.y:272
+		{
+			yyVAL.shrule = &ShardingRule{ID: yyDollar[4].str, TableName: yyDollar[5].str, Columns: yyDollar[7].strlist, HashFunction: yyDollar[8].str}
+		}
+	case 42:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line yacc/console/sql.y:212
+//line sql.y:278
 		{
 			yyVAL.sh_col = &ShardingColumn{ColName: yyDollar[4].str}
 		}
-	case 31:
+	case 43:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:218
+//line sql.y:284
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 32:
+	case 44:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:225
+//line sql.y:291
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 33:
+	case 45:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:231
+//line sql.y:297
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 38:
-		yyDollar = yyS[yypt-7 : yypt+1]
-//line yacc/console/sql.y:250
+	case 48:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:310
 		{
-			yyVAL.kr = &AddKeyRange{LowerBound: yyDollar[4].bytes, UpperBound: yyDollar[5].bytes, ShardID: yyDollar[6].str, KeyRangeID: yyDollar[7].str}
+			yyVAL.statement = yyDollar[1].kr
 		}
-	case 39:
+	case 49:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:314
+		{
+			yyVAL.statement = yyDollar[1].shrule
+		}
+	case 51:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:323
+		{
+			yyVAL.str = ""
+		}
+	case 52:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:327
+		{
+			yyVAL.str = string(yyDollar[2].str)
+		}
+	case 53:
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line sql.y:333
+		{
+			yyVAL.kr = &AddKeyRange{LowerBound: yyDollar[4].bytes, UpperBound: yyDollar[5].bytes, ShardID: yyDollar[6].str, KeyRangeID: yyDollar[7].str, KeyType: yyDollar[8].str}
+		}
+	case 54:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line yacc/console/sql.y:256
+//line sql.y:339
 		{
 			yyVAL.drop = &Drop{KeyRangeID: yyDollar[4].str}
 		}
-	case 40:
+	case 55:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line yacc/console/sql.y:262
+//line sql.y:345
 		{
 			yyVAL.lock = &Lock{KeyRangeID: yyDollar[4].str}
 		}
-	case 41:
+	case 56:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line yacc/console/sql.y:268
+//line sql.y:351
 		{
 			yyVAL.unlock = &Unlock{KeyRangeID: yyDollar[4].str}
 		}
-	case 42:
+	case 57:
 		yyDollar = yyS[yypt-8 : yypt+1]
-//line yacc/console/sql.y:275
+//line sql.y:358
 		{
 			yyVAL.split = &SplitKeyRange{KeyRangeID: yyDollar[4].str, KeyRangeFromID: yyDollar[6].str, Border: yyDollar[8].bytes}
 		}
-	case 43:
+	case 58:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line yacc/console/sql.y:281
+//line sql.y:364
 		{
 			yyVAL.kill = &Kill{Cmd: yyDollar[2].str}
 		}
-	case 44:
+	case 59:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:370
+		{
+			yyVAL.explain = &Explain{Query: yyDollar[3].str}
+		}
+	case 60:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line yacc/console/sql.y:287
+//line sql.y:376
 		{
-			yyVAL.move = &MoveKeyRange{KeyRangeID: yyDollar[4].str, DestShardID: yyDollar[5].str}
+			yyVAL.move = &MoveKeyRange{KeyRangeID: yyDollar[4].str, DestShardID: yyDollar[6].str}
 		}
-	case 45:
+	case 61:
 		yyDollar = yyS[yypt-6 : yypt+1]
-//line yacc/console/sql.y:293
+//line sql.y:382
 		{
-			yyVAL.unite = &UniteKeyRange{KeyRangeIDL: yyDollar[4].str, KeyRangeIDR: yyDollar[5].str}
+			yyVAL.unite = &UniteKeyRange{KeyRangeIDL: yyDollar[4].str, KeyRangeIDR: yyDollar[6].str}
 		}
-	case 46:
+	case 62:
 		yyDollar = yyS[yypt-2 : yypt+1]
-//line yacc/console/sql.y:299
+//line sql.y:388
 		{
 			yyVAL.listen = &Listen{addr: yyDollar[2].str}
 		}
-	case 47:
+	case 63:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:305
+//line sql.y:394
 		{
 			yyVAL.shutdown = &Shutdown{}
 		}
-	case 48:
+	case 64:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:313
+//line sql.y:402
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 49:
+	case 65:
 		yyDollar = yyS[yypt-1 : yypt+1]
-//line yacc/console/sql.y:319
+//line sql.y:408
 		{
 			yyVAL.str = string(yyDollar[1].str)
 		}
-	case 50:
+	case 66:
 		yyDollar = yyS[yypt-4 : yypt+1]
-//line yacc/console/sql.y:325
+//line sql.y:414
 		{
 			yyVAL.register_router = &RegisterRouter{Addr: yyDollar[3].str, ID: yyDollar[4].str}
 		}
-	case 51:
+	case 67:
 		yyDollar = yyS[yypt-3 : yypt+1]
-//line yacc/console/sql.y:331
+//line sql.y:420
 		{
 			yyVAL.unregister_router = &UnregisterRouter{ID: yyDollar[3].str}
 		}
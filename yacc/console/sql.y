@@ -14,9 +14,11 @@ package spqrparser
   show                   *Show
   kr                     *AddKeyRange
   sh_col                 *ShardingColumn
+  shrule                 *ShardingRule
   register_router        *RegisterRouter
   unregister_router      *UnregisterRouter
   kill                   *Kill
+  explain                *Explain
   drop                   *Drop
   lock                   *Lock
   shutdown               *Shutdown
@@ -28,6 +30,7 @@ package spqrparser
   str                    string
   byte                   byte
   bytes                []byte
+  strlist                []string
   int                    int
   bool                   bool
 }
@@ -46,24 +49,34 @@ package spqrparser
 // CMDS
 %type <statement> command
 
-%token <str> POOLS STATS LISTS SERVERS CLIENTS DATABASES
+%token <str> POOLS STATS LISTS SERVERS CLIENTS DATABASES HOSTS
 
 // routers
 %token <str> SHUTDOWN LISTEN REGISTER UNREGISTER ROUTER
 
 %token <str> CREATE ADD DROP LOCK UNLOCK SPLIT MOVE
 %token <str>  SHARDING COLUMN KEY RANGE SHARDS KEY_RANGES
+%token <str>  RULE COLUMNS TABLE HASH FUNCTION TYPE SHARDING_RULES
 %token <str>  BY FROM TO WITH UNITE
+%token <str>  EXPLAIN ROUTE
 
 %type <str> show_statement_type
 %type <str> kill_statement_type
 
 %type <show> show_stmt
 %type <kill> kill_stmt
+%type <explain> explain_stmt
 
 %type <sh_col> create_sharding_column_stmt
 
-%type <kr> add_stmt add_key_range_stmt
+%type <shrule> add_sharding_rule_stmt
+%type <strlist> sharding_rule_column_list
+%type<str> sharding_rule_id
+%type<str> sharding_rule_table_clause
+%type<str> sharding_rule_hash_function_clause
+
+%type <kr> add_key_range_stmt
+%type <statement> add_stmt
 %type <drop> drop_stmt drop_key_range_stmt
 %type <unlock> unlock_stmt unlock_key_range_stmt
 %type <lock> lock_stmt lock_key_range_stmt
@@ -82,6 +95,7 @@ package spqrparser
 %type<str> spqr_addr
 %type<bytes> key_range_spec_bound
 %type<str> key_range_id
+%type<str> key_range_type_clause
 %type<str> router_id
 %type<str> router_addr
 
@@ -155,6 +169,10 @@ command:
 	{
 		setParseTree(yylex, $1)
 	}
+	| explain_stmt
+	{
+		setParseTree(yylex, $1)
+	}
 
 reserved_keyword:
 POOLS
@@ -164,12 +182,14 @@ POOLS
 | SHARDS
 | STATS
 | KEY_RANGES
+| SHARDING_RULES
+| HOSTS
 
 show_statement_type:
 	reserved_keyword
 	{
 		switch v := string($1); v {
-		case ShowDatabasesStr, ShowPoolsStr, ShowShardsStr, ShowKeyRangesStr, ShowShardingColumns:
+		case ShowDatabasesStr, ShowPoolsStr, ShowShardsStr, ShowKeyRangesStr, ShowShardingColumns, ShowShardingRules, ShowHostsStr:
 			$$ = v
 		default:
 			$$ = ShowUnsupportedStr
@@ -206,6 +226,52 @@ key_range_spec_bound:
     {
       $$ = []byte($1)
     }
+    | key_range_spec_bound ',' STRING
+    {
+      $$ = append(append($1, ','), $3...)
+    }
+
+sharding_rule_id:
+	STRING
+	{
+		$$ = string($1)
+	}
+
+sharding_rule_column_list:
+	sharding_column_name
+	{
+		$$ = []string{$1}
+	}
+	| sharding_rule_column_list ',' sharding_column_name
+	{
+		$$ = append($1, $3)
+	}
+
+sharding_rule_table_clause:
+	/*empty*/
+	{
+		$$ = ""
+	}
+	| TABLE STRING
+	{
+		$$ = string($2)
+	}
+
+sharding_rule_hash_function_clause:
+	/*empty*/
+	{
+		$$ = ""
+	}
+	| HASH FUNCTION STRING
+	{
+		$$ = string($3)
+	}
+
+add_sharding_rule_stmt:
+	ADD SHARDING RULE sharding_rule_id sharding_rule_table_clause COLUMNS sharding_rule_column_list sharding_rule_hash_function_clause
+	{
+		$$ = &ShardingRule{ID: $4, TableName: $5, Columns: $7, HashFunction: $8}
+	}
 
 create_sharding_column_stmt:
 	CREATE SHARDING COLUMN sharding_column_name
@@ -241,14 +307,31 @@ lock_stmt:
 
 add_stmt:
 	add_key_range_stmt
+	{
+		$$ = $1
+	}
+	| add_sharding_rule_stmt
+	{
+		$$ = $1
+	}
 
 unlock_stmt:
 	unlock_key_range_stmt
 
+key_range_type_clause:
+	/*empty*/
+	{
+		$$ = ""
+	}
+	| TYPE STRING
+	{
+		$$ = string($2)
+	}
+
 add_key_range_stmt:
-	ADD KEY RANGE key_range_spec_bound key_range_spec_bound shard_id key_range_id
+	ADD KEY RANGE key_range_spec_bound key_range_spec_bound shard_id key_range_id key_range_type_clause
 	{
-		$$ = &AddKeyRange{LowerBound: $4, UpperBound: $5, ShardID: $6, KeyRangeID: $7}
+		$$ = &AddKeyRange{LowerBound: $4, UpperBound: $5, ShardID: $6, KeyRangeID: $7, KeyType: $8}
 	}
 
 drop_key_range_stmt:
@@ -282,16 +365,22 @@ KILL kill_statement_type
 		$$ = &Kill{Cmd: $2}
 	}
 
+explain_stmt:
+	EXPLAIN ROUTE STRING
+	{
+		$$ = &Explain{Query: $3}
+	}
+
 move_key_range_stmt:
 	MOVE KEY RANGE key_range_id TO shard_id
 	{
-		$$ = &MoveKeyRange{KeyRangeID: $4, DestShardID: $5}
+		$$ = &MoveKeyRange{KeyRangeID: $4, DestShardID: $6}
 	}
 
 unite_key_range_stmt:
 	UNITE KEY RANGE key_range_id WITH key_range_id
 	{
-		$$ = &UniteKeyRange{KeyRangeIDL: $4, KeyRangeIDR: $5}
+		$$ = &UniteKeyRange{KeyRangeIDL: $4, KeyRangeIDR: $6}
 	}
 
 listen_stmt: